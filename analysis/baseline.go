@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"fmt"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+)
+
+// Deviation kinds
+const (
+	DeviationUnexpected = "unexpected-open" // open but not in the allowlist
+	DeviationMissing    = "expected-closed" // in the allowlist but not open
+
+	// Only reported by ValidateChange
+	DeviationClosed    = "unexpected-closed" // closed by a change that didn't intend it
+	DeviationStillOpen = "still-open"        // meant to be closed by a change but isn't
+)
+
+// Deviation is a port whose state doesn't match the baseline
+type Deviation struct {
+	Host string
+	Port int
+	Kind string // see the Deviation kinds
+}
+
+func (d Deviation) String() string {
+	switch d.Kind {
+	case DeviationUnexpected:
+		return fmt.Sprintf("%s:%d is open but not allowed", d.Host, d.Port)
+	case DeviationClosed:
+		return fmt.Sprintf("%s:%d closed but wasn't meant to", d.Host, d.Port)
+	case DeviationStillOpen:
+		return fmt.Sprintf("%s:%d is still open but was meant to close", d.Host, d.Port)
+	default:
+		return fmt.Sprintf("%s:%d is expected open but isn't", d.Host, d.Port)
+	}
+}
+
+// CompareToBaseline checks results against a per-host allowlist of ports
+// that should be open, keyed by IP. It flags every open port missing from
+// its host's allowlist (all open ports, for a host with no entry) and every
+// allowlisted port that isn't open, including on baseline hosts that don't
+// appear in results at all. No deviations means the scan passes.
+//
+// Deviations are sorted by host and port.
+func CompareToBaseline(results []models.HostResult, baseline map[string][]int) []Deviation {
+	var deviations []Deviation
+	seen := make(map[string]bool, len(results))
+
+	for _, host := range results {
+		seen[host.IP] = true
+
+		allowed := make(map[int]bool)
+		for _, port := range baseline[host.IP] {
+			allowed[port] = true
+		}
+
+		open := make(map[int]bool)
+		for _, port := range host.Ports {
+			if !port.Open {
+				continue
+			}
+			open[port.Port] = true
+			if !allowed[port.Port] {
+				deviations = append(deviations, Deviation{host.IP, port.Port, DeviationUnexpected})
+			}
+		}
+
+		for port := range allowed {
+			if !open[port] {
+				deviations = append(deviations, Deviation{host.IP, port, DeviationMissing})
+			}
+		}
+	}
+
+	for ip, ports := range baseline {
+		if seen[ip] {
+			continue
+		}
+		listed := make(map[int]bool)
+		for _, port := range ports {
+			if !listed[port] {
+				listed[port] = true
+				deviations = append(deviations, Deviation{ip, port, DeviationMissing})
+			}
+		}
+	}
+
+	sortDeviations(deviations)
+	return deviations
+}
+
+// sortDeviations sorts deviations by host and port
+func sortDeviations(deviations []Deviation) {
+	sort.Slice(deviations, func(i, j int) bool {
+		a, b := deviations[i], deviations[j]
+		if a.Host != b.Host {
+			return utils.CompareIPs(a.Host, b.Host)
+		}
+		return a.Port < b.Port
+	})
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"netscan/models"
+	"strconv"
+	"strings"
+)
+
+// ExpectedChange is a port a change is meant to open or close
+type ExpectedChange struct {
+	Host string
+	Port int
+	Open bool // true if the change should open the port, false to close it
+}
+
+// ParseChangeSpec reads the changes a firewall or service change is meant
+// to make, one per line: +host:port for a port that should open and
+// -host:port for one that should close, e.g. "+10.0.0.5:443". Blank lines
+// and lines starting with # are skipped.
+func ParseChangeSpec(r io.Reader) ([]ExpectedChange, error) {
+	var changes []ExpectedChange
+	lines := bufio.NewScanner(r)
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var change ExpectedChange
+		switch line[0] {
+		case '+':
+			change.Open = true
+		case '-':
+		default:
+			return nil, fmt.Errorf("line %d: %q must start with + or -", n, line)
+		}
+
+		host, portStr, err := net.SplitHostPort(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("line %d: invalid port %q", n, portStr)
+		}
+		change.Host, change.Port = host, port
+		changes = append(changes, change)
+	}
+	return changes, lines.Err()
+}
+
+// ValidateChange checks that the difference between scans taken before and
+// after a change is exactly what expected says it should be. It flags ports
+// that opened without being expected to, ports that closed without being
+// expected to, expected opens that aren't open afterwards and expected
+// closes that are still open. Ports whose state didn't change and that
+// aren't in expected are ignored, so both scans should cover the same
+// hosts and ports. No deviations means the change went as intended.
+//
+// Deviations are sorted by host and port.
+func ValidateChange(before, after []models.HostResult, expected []ExpectedChange) []Deviation {
+	type key struct {
+		host string
+		port int
+	}
+	openSet := func(results []models.HostResult) map[key]bool {
+		open := make(map[key]bool)
+		for _, host := range results {
+			for _, port := range host.Ports {
+				if port.Open {
+					open[key{host.IP, port.Port}] = true
+				}
+			}
+		}
+		return open
+	}
+	wasOpen, isOpen := openSet(before), openSet(after)
+
+	intended := make(map[key]bool, len(expected))
+	for _, change := range expected {
+		intended[key{change.Host, change.Port}] = change.Open
+	}
+
+	var deviations []Deviation
+	for k := range isOpen {
+		if open, ok := intended[k]; !wasOpen[k] && (!ok || !open) {
+			deviations = append(deviations, Deviation{k.host, k.port, DeviationUnexpected})
+		}
+	}
+	for k := range wasOpen {
+		if open, ok := intended[k]; !isOpen[k] && (!ok || open) {
+			deviations = append(deviations, Deviation{k.host, k.port, DeviationClosed})
+		}
+	}
+	for k, open := range intended {
+		switch {
+		case open && !isOpen[k] && !wasOpen[k]:
+			deviations = append(deviations, Deviation{k.host, k.port, DeviationMissing})
+		case !open && isOpen[k] && wasOpen[k]:
+			deviations = append(deviations, Deviation{k.host, k.port, DeviationStillOpen})
+		}
+	}
+
+	sortDeviations(deviations)
+	return deviations
+}
@@ -0,0 +1,149 @@
+// Package analysis works on completed scan results: comparing runs,
+// checking them against expectations and summarizing them.
+package analysis
+
+import (
+	"fmt"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+)
+
+// DiffHosts compares the live hosts of two sweeps and returns the addresses
+// that appeared in new and the ones that disappeared from old, each sorted
+// by IP. Port details are ignored; only host presence matters.
+func DiffHosts(old, new []models.HostResult) (appeared, disappeared []string) {
+	oldLive := liveSet(old)
+	newLive := liveSet(new)
+
+	for ip := range newLive {
+		if !oldLive[ip] {
+			appeared = append(appeared, ip)
+		}
+	}
+	for ip := range oldLive {
+		if !newLive[ip] {
+			disappeared = append(disappeared, ip)
+		}
+	}
+
+	sortIPs(appeared)
+	sortIPs(disappeared)
+	return appeared, disappeared
+}
+
+// liveSet returns the addresses of the live hosts in results
+func liveSet(results []models.HostResult) map[string]bool {
+	live := make(map[string]bool, len(results))
+	for _, host := range results {
+		if host.Alive {
+			live[host.IP] = true
+		}
+	}
+	return live
+}
+
+func sortIPs(ips []string) {
+	sort.Slice(ips, func(i, j int) bool {
+		return utils.CompareIPs(ips[i], ips[j])
+	})
+}
+
+// ScanDiff is what changed between two scans of the same targets
+type ScanDiff struct {
+	NewHosts    []string     `json:"new_hosts"`
+	GoneHosts   []string     `json:"gone_hosts"`
+	OpenedPorts []PortChange `json:"opened_ports"`
+	ClosedPorts []PortChange `json:"closed_ports"`
+}
+
+// PortChange is a port that opened or closed between two scans
+type PortChange struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Service  string `json:"service,omitempty"`
+}
+
+func (c PortChange) String() string {
+	s := fmt.Sprintf("%s:%d/%s", c.Host, c.Port, c.Protocol)
+	if c.Service != "" {
+		s += " (" + c.Service + ")"
+	}
+	return s
+}
+
+// Empty reports whether nothing changed
+func (d ScanDiff) Empty() bool {
+	return len(d.NewHosts) == 0 && len(d.GoneHosts) == 0 && len(d.OpenedPorts) == 0 && len(d.ClosedPorts) == 0
+}
+
+// DiffScans compares two scans, such as nightly snapshots of an asset
+// inventory, and returns the live hosts that appeared and disappeared (see
+// DiffHosts) and the ports open in one but not the other. Ports on hosts
+// that appeared or disappeared count as opened or closed too, so every
+// newly reachable service is listed. Ports are sorted by host, port and
+// protocol, and the slices are empty rather than nil when nothing changed.
+func DiffScans(old, new []models.HostResult) ScanDiff {
+	diff := ScanDiff{
+		NewHosts:    []string{},
+		GoneHosts:   []string{},
+		OpenedPorts: []PortChange{},
+		ClosedPorts: []PortChange{},
+	}
+	appeared, disappeared := DiffHosts(old, new)
+	diff.NewHosts = append(diff.NewHosts, appeared...)
+	diff.GoneHosts = append(diff.GoneHosts, disappeared...)
+
+	wasOpen, isOpen := openPorts(old), openPorts(new)
+	for k, change := range isOpen {
+		if _, ok := wasOpen[k]; !ok {
+			diff.OpenedPorts = append(diff.OpenedPorts, change)
+		}
+	}
+	for k, change := range wasOpen {
+		if _, ok := isOpen[k]; !ok {
+			diff.ClosedPorts = append(diff.ClosedPorts, change)
+		}
+	}
+
+	sortPortChanges(diff.OpenedPorts)
+	sortPortChanges(diff.ClosedPorts)
+	return diff
+}
+
+// openPorts returns the open ports in results keyed by host, port and
+// protocol, with TCP for ports that don't name one
+func openPorts(results []models.HostResult) map[PortChange]PortChange {
+	open := make(map[PortChange]PortChange)
+	for _, host := range results {
+		for _, port := range host.Ports {
+			if !port.Open {
+				continue
+			}
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = models.ProtoTCP
+			}
+			key := PortChange{Host: host.IP, Port: port.Port, Protocol: protocol}
+			change := key
+			change.Service = port.Service
+			open[key] = change
+		}
+	}
+	return open
+}
+
+// sortPortChanges sorts changes by host, port and protocol
+func sortPortChanges(changes []PortChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Host != b.Host {
+			return utils.CompareIPs(a.Host, b.Host)
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Protocol < b.Protocol
+	})
+}
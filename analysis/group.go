@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+)
+
+// GroupHosts merges results that belong to the same machine, such as the
+// IPv4 and IPv6 addresses of a dual-stack host. Each result's identity is
+// looked up in identity by IP first, then falls back to its Hostname, and
+// finally to the IP itself, so hosts with nothing in common stay separate.
+//
+// A merged host lists every address in Addresses, sorted with IPv4 first,
+// and takes the first of them as its IP. Its ports are the union of its
+// members' ports, with a port counted open if it was open on any address.
+// It's alive if any member was. Results are returned sorted by IP.
+func GroupHosts(results []models.HostResult, identity map[string]string) []models.HostResult {
+	var order []string
+	groups := make(map[string][]models.HostResult)
+
+	for _, host := range results {
+		key := identity[host.IP]
+		if key == "" {
+			key = host.Hostname
+		}
+		if key == "" {
+			key = host.IP
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], host)
+	}
+
+	merged := make([]models.HostResult, 0, len(order))
+	for _, key := range order {
+		merged = append(merged, mergeHosts(groups[key]))
+	}
+	sortHosts(merged)
+	return merged
+}
+
+// mergeHosts combines the results for one machine's addresses
+func mergeHosts(members []models.HostResult) models.HostResult {
+	if len(members) == 1 {
+		return members[0]
+	}
+
+	var addresses []string
+	for _, host := range members {
+		addresses = append(addresses, host.IP)
+	}
+	sortIPs(addresses)
+
+	merged := models.HostResult{IP: addresses[0], Addresses: addresses}
+	ports := make(map[int]models.PortResult)
+	for _, host := range members {
+		merged.Alive = merged.Alive || host.Alive
+		merged.PortsTruncated = merged.PortsTruncated || host.PortsTruncated
+		merged.SuspectedHoneypot = merged.SuspectedHoneypot || host.SuspectedHoneypot
+		if merged.Hostname == "" {
+			merged.Hostname = host.Hostname
+		}
+		if merged.Workgroup == "" {
+			merged.Workgroup = host.Workgroup
+		}
+		for _, service := range host.Advertised {
+			if !contains(merged.Advertised, service) {
+				merged.Advertised = append(merged.Advertised, service)
+			}
+		}
+		if host.Latency > 0 && (merged.Latency == 0 || host.Latency < merged.Latency) {
+			merged.Latency = host.Latency
+		}
+		merged.ScanDuration = max(merged.ScanDuration, host.ScanDuration)
+
+		for _, port := range host.Ports {
+			if existing, ok := ports[port.Port]; !ok || (port.Open && !existing.Open) {
+				ports[port.Port] = port
+			}
+		}
+	}
+
+	for _, port := range ports {
+		merged.Ports = append(merged.Ports, port)
+	}
+	sort.Slice(merged.Ports, func(i, j int) bool {
+		return merged.Ports[i].Port < merged.Ports[j].Port
+	})
+
+	switch {
+	case merged.OpenCount() > 0:
+		merged.Status = models.StatusOpenPorts
+	case merged.Alive:
+		merged.Status = models.StatusNoPorts
+	default:
+		merged.Status = members[0].Status
+	}
+	return merged
+}
+
+func sortHosts(hosts []models.HostResult) {
+	sort.Slice(hosts, func(i, j int) bool {
+		return utils.CompareIPs(hosts[i].IP, hosts[j].IP)
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
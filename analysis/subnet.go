@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"net"
+	"netscan/models"
+	"sort"
+)
+
+// SubnetSummary rolls up the results for one network
+type SubnetSummary struct {
+	Network    string
+	HostsAlive int
+	OpenPorts  int
+
+	// Services lists the services seen on open ports, most common first
+	Services []ServiceCount
+}
+
+// ServiceCount is how many open ports in a subnet run a service
+type ServiceCount struct {
+	Service string
+	Count   int
+}
+
+// SummarizeBySubnet rolls results up per network, keyed by the CIDR strings
+// in networks as given. A host counts towards every network containing one
+// of its addresses; hosts outside all of them are left out, as are networks
+// that don't parse. Every valid network gets a summary, even an empty one.
+func SummarizeBySubnet(results []models.HostResult, networks []string) map[string]SubnetSummary {
+	summaries := make(map[string]SubnetSummary)
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			continue
+		}
+
+		summary := SubnetSummary{Network: network}
+		services := make(map[string]int)
+		for _, host := range results {
+			if !host.Alive || !inNetwork(ipNet, host) {
+				continue
+			}
+			summary.HostsAlive++
+			for _, port := range host.Ports {
+				if !port.Open {
+					continue
+				}
+				summary.OpenPorts++
+				service := port.Service
+				if service == "" {
+					service = "Unknown"
+				}
+				services[service]++
+			}
+		}
+
+		for service, count := range services {
+			summary.Services = append(summary.Services, ServiceCount{Service: service, Count: count})
+		}
+		sort.Slice(summary.Services, func(i, j int) bool {
+			a, b := summary.Services[i], summary.Services[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Service < b.Service
+		})
+
+		summaries[network] = summary
+	}
+
+	return summaries
+}
+
+// inNetwork reports whether any of host's addresses fall inside ipNet
+func inNetwork(ipNet *net.IPNet, host models.HostResult) bool {
+	addresses := host.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{host.IP}
+	}
+	for _, addr := range addresses {
+		if ip := net.ParseIP(addr); ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package banner
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTP probes sent to services that stay silent after the connection is
+// made, see probes. Services that speak first (SSH, FTP, SMTP) never
+// receive a probe.
+var (
+	httpProbe     = []byte("GET / HTTP/1.0\r\n\r\n")
+	httpProbeFast = []byte("GET / HTTP/1.1\r\nHost: \r\nConnection: close\r\n\r\n")
+)
+
+// Result is what a banner grab learned about a service
+type Result struct {
+	Banner string
+
+	// Service names the service when its response gave it away, e.g.
+	// "SSH" for a banner starting SSH- or "Redis" for a +PONG, whatever
+	// port it's on. It's "" otherwise.
+	Service string
+
+	// Silent is set when the service accepted the connection but sent
+	// nothing before the timeout, even after a probe: a listening socket
+	// with no protocol handler behind it, or one waiting for input we
+	// don't know how to give
+	Silent bool
+
+	// SecurityHeaders maps each security header an HTTP service sent to
+	// its value, when Options.SecurityHeaders is set. It's nil for
+	// non-HTTP services and empty for HTTP services that sent none.
+	SecurityHeaders map[string]string
+}
+
+// Options controls a banner grab
+type Options struct {
+	// Timeout is the total time allowed, including any probe
+	Timeout time.Duration
+
+	// MaxBytes is the most banner data captured. Banners are stored in
+	// full up to this size; shortening them for display is up to the
+	// caller.
+	MaxBytes int
+
+	// Fast skips ports that need a TLS handshake and uses the HTTP/1.1
+	// probe, for quick discovery scans
+	Fast bool
+
+	// SecurityHeaders reads HTTP responses through to the end of the
+	// headers and records which security headers are present. Headers
+	// beyond MaxBytes are missed.
+	SecurityHeaders bool
+
+	// Linger keeps reading for up to this long after the first data
+	// arrives, for services that send their banner in pieces. Whatever was
+	// read when it runs out (or the overall Timeout does) is kept. Zero
+	// takes only the first chunk.
+	Linger time.Duration
+
+	// Retries is how many more times to read when a read comes back empty
+	// without timing out (e.g. an early EOF), pausing RetryDelay first
+	// each time, for services that are slow to speak. Retries never run
+	// past Timeout.
+	Retries int
+
+	// RetryDelay is the pause before each retry. Zero uses
+	// DefaultRetryDelay.
+	RetryDelay time.Duration
+}
+
+// DefaultRetryDelay is the pause before retrying an empty read when
+// Options.RetryDelay isn't set
+const DefaultRetryDelay = 100 * time.Millisecond
+
+// Preset options for GrabBanner and GrabBannerFast
+var (
+	DefaultOptions = Options{Timeout: 2 * time.Second, MaxBytes: 1024}
+	FastOptions    = Options{Timeout: 500 * time.Millisecond, MaxBytes: 512, Fast: true}
+)
+
+// GrabBanner reads the service banner from conn, sending the port's probe
+// (see RegisterProbe) only if the server doesn't speak first
+func GrabBanner(conn net.Conn, port int) Result {
+	return Grab(conn, port, DefaultOptions)
+}
+
+// Faster banner grabbing with shorter timeout
+func GrabBannerFast(conn net.Conn, port int) Result {
+	return Grab(conn, port, FastOptions)
+}
+
+// Grab reads the service banner from conn using opts
+func Grab(conn net.Conn, port int, opts Options) Result {
+	data, silent := grab(conn, probeFor(port, opts), opts)
+	if len(data) == 0 {
+		return Result{Silent: silent}
+	}
+
+	result := Result{Banner: clean(string(data))}
+	if service, banner := identify(data); service != "" {
+		result.Service = service
+		if banner != "" {
+			result.Banner = banner
+		}
+	}
+	if opts.SecurityHeaders && isHTTP(data) {
+		result.SecurityHeaders = parseSecurityHeaders(data)
+	}
+	return result
+}
+
+// grab listens for an unsolicited banner first and only sends probe if the
+// server stays silent for the first part of the timeout. Sending a probe to a
+// service that was about to speak (or that isn't what the port suggests) can
+// confuse it, so the listen phase always comes first.
+//
+// It returns the data read, or reports whether the service stayed silent if
+// there was none. With opts.SecurityHeaders an HTTP response is read up to
+// the end of its headers rather than just the first chunk, and with
+// opts.Linger reading carries on for a while after the first chunk. Either
+// way a deadline partway through keeps what had arrived.
+func grab(conn net.Conn, probe []byte, opts Options) (data []byte, silent bool) {
+	timeout := opts.Timeout
+	listen := timeout
+	if probe != nil {
+		listen = timeout / 2
+	}
+	deadline := time.Now().Add(timeout)
+
+	buffer := make([]byte, opts.MaxBytes)
+
+	listenEnd := time.Now().Add(listen)
+	conn.SetReadDeadline(listenEnd)
+	n, err := readRetrying(conn, buffer, opts, listenEnd)
+	if n == 0 && probe != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		// Server stayed silent, so it's waiting for the client to speak
+		conn.SetReadDeadline(deadline)
+		if _, err := conn.Write(probe); err != nil {
+			return nil, false
+		}
+		n, err = readRetrying(conn, buffer, opts, deadline)
+	}
+	if n == 0 {
+		return nil, errors.Is(err, os.ErrDeadlineExceeded)
+	}
+
+	switch {
+	case opts.SecurityHeaders && isHTTP(buffer[:n]):
+		n = readMore(conn, buffer, n, err, headersComplete)
+	case opts.Linger > 0:
+		if linger := time.Now().Add(opts.Linger); linger.Before(deadline) {
+			conn.SetReadDeadline(linger)
+		}
+		n = readMore(conn, buffer, n, err, nil)
+	}
+
+	return buffer[:n], false
+}
+
+// readRetrying reads into buffer, retrying up to opts.Retries times after
+// a pause when a read returns nothing but hasn't timed out. It stops early
+// rather than pause past until.
+func readRetrying(conn net.Conn, buffer []byte, opts Options, until time.Time) (int, error) {
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+
+	n, err := conn.Read(buffer)
+	for retry := 0; n == 0 && retry < opts.Retries; retry++ {
+		if errors.Is(err, os.ErrDeadlineExceeded) || time.Now().Add(delay).After(until) {
+			break
+		}
+		time.Sleep(delay)
+		n, err = conn.Read(buffer)
+	}
+	return n, err
+}
+
+// readMore keeps filling buffer after its first n bytes until a read fails
+// (including on the deadline), the buffer is full or done reports the data
+// complete. It returns the new length; nothing already read is lost.
+func readMore(conn net.Conn, buffer []byte, n int, err error, done func([]byte) bool) int {
+	for err == nil && n < len(buffer) && (done == nil || !done(buffer[:n])) {
+		var m int
+		m, err = conn.Read(buffer[n:])
+		n += m
+	}
+	return n
+}
+
+// clean flattens a banner onto one line
+func clean(banner string) string {
+	banner = strings.ReplaceAll(banner, "\r\n", " ")
+	banner = strings.ReplaceAll(banner, "\n", " ")
+	return strings.TrimSpace(banner)
+}
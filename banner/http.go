@@ -0,0 +1,161 @@
+package banner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SecurityHeaders are the HTTP response headers recorded by a header audit.
+// Each one hardens browsers against a class of attack, so a missing one is
+// worth flagging.
+var SecurityHeaders = []string{
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Permissions-Policy",
+}
+
+// isHTTP reports whether data looks like the start of an HTTP response
+func isHTTP(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("HTTP/"))
+}
+
+// headersComplete reports whether data holds the full header block of an
+// HTTP response
+func headersComplete(data []byte) bool {
+	return bytes.Contains(data, []byte("\r\n\r\n")) || bytes.Contains(data, []byte("\n\n"))
+}
+
+// parseSecurityHeaders returns the security headers present in an HTTP
+// response, keyed by canonical header name. A truncated header block is
+// parsed as far as it goes.
+func parseSecurityHeaders(data []byte) map[string]string {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	if _, err := r.ReadLine(); err != nil { // status line
+		return map[string]string{}
+	}
+	// ReadMIMEHeader returns what it parsed even when it hits the end of a
+	// truncated block
+	header, _ := r.ReadMIMEHeader()
+
+	found := make(map[string]string)
+	for _, name := range SecurityHeaders {
+		if value := header.Get(name); value != "" {
+			found[name] = value
+		}
+	}
+	return found
+}
+
+// MaxHTTPBody is how much of a response body FingerprintHTTP reads looking
+// for the page title
+const MaxHTTPBody = 4096
+
+var titleRE = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// HTTPInfo is what an HTTP server's response to a request for / says
+// about it
+type HTTPInfo struct {
+	Status int
+	Server string
+	Title  string
+}
+
+// FingerprintHTTP requests / from the HTTP server at authority (host:port,
+// sent as the Host header) and returns the status, Server header and page
+// title of the response. A redirect to another path on the same server is
+// followed once; a redirect elsewhere is reported as is. dial opens each
+// connection, through a TLS handshake for HTTPS, and timeout bounds each
+// request.
+func FingerprintHTTP(dial func() (net.Conn, error), authority string, timeout time.Duration) (HTTPInfo, error) {
+	info, location, err := requestHTTP(dial, authority, "/", timeout)
+	if err != nil || location == "" {
+		return info, err
+	}
+
+	target, err := url.Parse(location)
+	if err != nil || !sameServer(target, authority) {
+		return info, nil
+	}
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	if redirected, _, err := requestHTTP(dial, authority, path, timeout); err == nil {
+		return redirected, nil
+	}
+	return info, nil
+}
+
+// requestHTTP sends a GET for path over a new connection and parses the
+// response, returning the Location of a redirect
+func requestHTTP(dial func() (net.Conn, error), authority, path string, timeout time.Duration) (info HTTPInfo, location string, err error) {
+	conn, err := dial()
+	if err != nil {
+		return info, "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	req := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUser-Agent: netscan\r\nAccept: text/html\r\nConnection: close\r\n\r\n", path, authority)
+	if _, err := io.WriteString(conn, req); err != nil {
+		return info, "", err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodGet})
+	if err != nil {
+		return info, "", err
+	}
+	defer resp.Body.Close()
+
+	info.Status = resp.StatusCode
+	info.Server = resp.Header.Get("Server")
+	// A deadline or a short body still leaves what was read
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxHTTPBody))
+	info.Title = pageTitle(body)
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		location = resp.Header.Get("Location")
+	}
+	return info, location, nil
+}
+
+// sameServer reports whether a redirect target points back at authority
+func sameServer(target *url.URL, authority string) bool {
+	if target.Host == "" {
+		return target.Scheme == ""
+	}
+	if target.Host == authority {
+		return true
+	}
+	// A target with no port means the scheme's default, so only the host
+	// has to match when authority is on that port too
+	host, port, _ := net.SplitHostPort(authority)
+	defaultPort := map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	return target.Port() == "" && strings.EqualFold(target.Hostname(), host) && port == defaultPort
+}
+
+// pageTitle returns the text of an HTML page's <title>, or "" if there's
+// none
+func pageTitle(body []byte) string {
+	m := titleRE.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+}
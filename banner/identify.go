@@ -0,0 +1,82 @@
+package banner
+
+import (
+	"bytes"
+	"strings"
+)
+
+// signature recognizes a service by the start of what it sends
+type signature struct {
+	service string
+	match   func(data []byte) bool
+}
+
+// signatures are tried in order, so more specific ones come first
+var signatures = []signature{
+	{"SSH", prefix("SSH-")},
+	{"HTTP", prefix("HTTP/")},
+	{"FTP", greeting("220", "FTP")},
+	{"SMTP", greeting("220", "SMTP")},
+	{"POP3", prefix("+OK")},
+	{"IMAP", prefix("* OK")},
+	{"VNC", prefix("RFB ")},
+	{"Redis", prefix("+PONG")},
+	{"Redis", prefix("-NOAUTH")},
+	{"Redis", prefix("-DENIED Redis")},
+	{"Memcached", prefix("STAT pid ")},
+	{"AMQP", prefix("AMQP")},
+}
+
+// prefix matches data that starts with p
+func prefix(p string) func([]byte) bool {
+	return func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte(p))
+	}
+}
+
+// greeting matches a line-based greeting with the given status code that
+// mentions word, in any case, on its first line
+func greeting(code, word string) func([]byte) bool {
+	return func(data []byte) bool {
+		if !bytes.HasPrefix(data, []byte(code)) {
+			return false
+		}
+		line, _, _ := bytes.Cut(data, []byte("\n"))
+		return strings.Contains(strings.ToUpper(string(line)), word)
+	}
+}
+
+// identify recognizes a service from the first data it sent, returning its
+// name and a readable banner in place of binary data, or "" if it's none
+// it knows
+func identify(data []byte) (service, banner string) {
+	for _, sig := range signatures {
+		if sig.match(data) {
+			return sig.service, ""
+		}
+	}
+	if version, ok := mysqlHandshake(data); ok {
+		return "MySQL", "MySQL " + version
+	}
+	return "", ""
+}
+
+// mysqlHandshake parses the initial handshake packet a MySQL (or MariaDB)
+// server sends on connect: a 3-byte length, a sequence number of 0,
+// protocol version 10 and the NUL-terminated server version. It returns
+// the server version.
+func mysqlHandshake(data []byte) (string, bool) {
+	if len(data) < 6 || data[3] != 0 || data[4] != 10 {
+		return "", false
+	}
+	length := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+	end := bytes.IndexByte(data[5:], 0)
+	if length < 2 || end < 1 || end+1 > length {
+		return "", false
+	}
+	version := string(data[5 : 5+end])
+	if strings.IndexFunc(version, func(r rune) bool { return r < ' ' || r > '~' }) >= 0 {
+		return "", false
+	}
+	return version, true
+}
@@ -0,0 +1,48 @@
+package banner
+
+import (
+	"bytes"
+	"sync"
+)
+
+// probes maps ports to the payload sent when the service there stays
+// silent after the connection is made, see RegisterProbe
+var (
+	probesMu sync.RWMutex
+	probes   = map[int][]byte{
+		25:    []byte("EHLO netscan\r\n"), // SMTP servers that hold back their greeting
+		80:    httpProbe,
+		443:   httpProbe, // over TLS, see Handshake
+		587:   []byte("EHLO netscan\r\n"),
+		6379:  []byte("PING\r\n"), // Redis
+		8080:  httpProbe,
+		8443:  httpProbe,
+		11211: []byte("stats\r\n"), // Memcached
+	}
+)
+
+// RegisterProbe sets the payload sent to services on port that stay silent
+// after the connection is made, replacing any built-in one. Services that
+// speak first never receive it. A nil probe removes the port's probe.
+func RegisterProbe(port int, probe []byte) {
+	probesMu.Lock()
+	defer probesMu.Unlock()
+	if probe == nil {
+		delete(probes, port)
+		return
+	}
+	probes[port] = bytes.Clone(probe)
+}
+
+// probeFor returns the payload for port, or nil if it has none. The
+// built-in HTTP probe becomes the HTTP/1.1 one with opts.Fast.
+func probeFor(port int, opts Options) []byte {
+	probesMu.RLock()
+	probe := probes[port]
+	probesMu.RUnlock()
+
+	if opts.Fast && bytes.Equal(probe, httpProbe) {
+		return httpProbeFast
+	}
+	return probe
+}
@@ -0,0 +1,139 @@
+package banner
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"netscan/models"
+	"os"
+	"time"
+)
+
+// TLSPorts are well-known ports whose services expect a TLS handshake as
+// soon as the connection opens
+var TLSPorts = map[int]bool{
+	443:  true, // HTTPS
+	465:  true, // SMTPS
+	636:  true, // LDAPS
+	853:  true, // DNS over TLS
+	990:  true, // FTPS
+	993:  true, // IMAPS
+	995:  true, // POP3S
+	5986: true, // WinRM over HTTPS
+	8443: true, // HTTPS alternate
+}
+
+// Handshake performs a TLS client handshake over conn and returns the TLS
+// connection to read the banner from. If the server asks for a client
+// certificate, cert is presented; with a nil cert an empty certificate is
+// sent instead, as a browser would. The server's own certificate is not
+// verified since scans target arbitrary hosts.
+//
+// The returned TLSInfo records whether a client certificate was requested
+// and accepted. It's returned even when the handshake fails after the
+// request, so a rejected certificate can be told apart from a port that
+// doesn't speak TLS at all.
+func Handshake(conn net.Conn, serverName string, cert *tls.Certificate, timeout time.Duration) (net.Conn, *models.TLSInfo, error) {
+	info := &models.TLSInfo{}
+	sent := false
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			info.ClientCertRequested = true
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			sent = true
+			return cert, nil
+		},
+	})
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if err := tlsConn.Handshake(); err != nil {
+		if info.ClientCertRequested {
+			// The server's certificate came before its request
+			describePeer(info, tlsConn)
+			return nil, info, err
+		}
+		return nil, nil, err
+	}
+
+	// In TLS 1.3 the client finishes its side of the handshake before the
+	// server has checked the certificate, so a rejection only arrives as an
+	// alert on the first read. Wait briefly for one before calling the
+	// certificate accepted, keeping any data that turns up instead.
+	result := net.Conn(tlsConn)
+	if info.ClientCertRequested && tlsConn.ConnectionState().Version == tls.VersionTLS13 {
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(timeout / 4))
+		n, err := tlsConn.Read(buf)
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, info, err
+		}
+		result = &replayConn{Conn: tlsConn, pending: buf[:n]}
+	}
+
+	info.Completed = true
+	info.ClientCertAccepted = sent
+	describePeer(info, tlsConn)
+	return result, info, nil
+}
+
+// GrabTLSInfo performs a TLS handshake over conn with host as the SNI
+// server name and returns what it learned about the server's certificate.
+// conn is left open; the caller closes it.
+func GrabTLSInfo(conn net.Conn, host string) (*models.TLSInfo, error) {
+	_, info, err := Handshake(conn, host, nil, DefaultOptions.Timeout)
+	return info, err
+}
+
+// describePeer copies the details of the server's certificate into info
+func describePeer(info *models.TLSInfo, conn *tls.Conn) {
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return
+	}
+	cert := certs[0]
+	info.Subject = certName(cert.Subject)
+	info.Issuer = certName(cert.Issuer)
+	info.NotAfter = cert.NotAfter
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+}
+
+// certName returns name's common name, or the whole distinguished name if
+// it has none
+func certName(name pkix.Name) string {
+	if name.CommonName != "" {
+		return name.CommonName
+	}
+	return name.String()
+}
+
+// IsTLSAlert reports whether a banner is a TLS alert record, which is how a
+// TLS server answers plaintext it can't parse
+func IsTLSAlert(banner string) bool {
+	return len(banner) >= 2 && banner[0] == 0x15 && banner[1] == 0x03
+}
+
+// replayConn returns data already read from Conn before reading more
+type replayConn struct {
+	net.Conn
+	pending []byte
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	if len(c.pending) > 0 {
+		n := copy(b, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
@@ -0,0 +1,89 @@
+package banner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// versionPattern extracts a product and version from a banner. The first
+// submatch is the version, appended to product; with no submatch the
+// product alone is returned.
+type versionPattern struct {
+	re      *regexp.Regexp
+	product string
+}
+
+// versionPatterns groups the patterns by the kind of service they apply to,
+// keyed by the lowercased prefix of the service names that use them
+var versionPatterns = map[string][]versionPattern{
+	"ssh": {
+		{regexp.MustCompile(`SSH-[\d.]+-OpenSSH_([\w.]+)`), "OpenSSH"},
+		{regexp.MustCompile(`SSH-[\d.]+-dropbear_([\w.]+)`), "Dropbear"},
+	},
+	"http": {
+		{regexp.MustCompile(`(?i)\bServer: Apache/([\w.]+)`), "Apache"},
+		{regexp.MustCompile(`(?i)\bServer: nginx/([\w.]+)`), "nginx"},
+		{regexp.MustCompile(`(?i)\bServer: openresty/([\w.]+)`), "OpenResty"},
+		{regexp.MustCompile(`(?i)\bServer: Microsoft-IIS/([\w.]+)`), "IIS"},
+		{regexp.MustCompile(`(?i)\bServer: lighttpd/([\w.]+)`), "lighttpd"},
+	},
+	"ftp": {
+		{regexp.MustCompile(`(?i)\(vsFTPd ([\w.]+)\)`), "vsftpd"},
+		{regexp.MustCompile(`ProFTPD ([\w.]+)`), "ProFTPD"},
+		{regexp.MustCompile(`Pure-FTPd`), "Pure-FTPd"},
+	},
+	"smtp": {
+		{regexp.MustCompile(`ESMTP Exim ([\w.]+)`), "Exim"},
+		{regexp.MustCompile(`ESMTP Postfix`), "Postfix"},
+		{regexp.MustCompile(`ESMTP Sendmail ([\w.]+)`), "Sendmail"},
+	},
+	"mysql": {
+		{regexp.MustCompile(`^MySQL [\d.]+-([\d.]+)-MariaDB`), "MariaDB"},
+		{regexp.MustCompile(`^MySQL ([\d.]+)`), "MySQL"},
+	},
+}
+
+// serviceKinds maps service names that don't start with their kind's key
+// to it
+var serviceKinds = map[string]string{
+	"submission": "smtp",
+	"ftps":       "ftp",
+}
+
+// ParseVersion extracts the product and version a banner announces, such
+// as "OpenSSH 8.9p1" from "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3", or returns ""
+// if it doesn't recognize one. service, the name the port goes by (e.g.
+// "SSH" or "HTTP-Alt"), picks which patterns to try; for one it doesn't
+// know, all of them are tried.
+func ParseVersion(service, banner string) string {
+	for _, p := range patternsFor(service) {
+		m := p.re.FindStringSubmatch(banner)
+		switch {
+		case m == nil:
+		case len(m) > 1:
+			return p.product + " " + m[1]
+		default:
+			return p.product
+		}
+	}
+	return ""
+}
+
+// patternsFor returns the version patterns that apply to service
+func patternsFor(service string) []versionPattern {
+	name := strings.ToLower(service)
+	if kind, ok := serviceKinds[name]; ok {
+		name = kind
+	}
+	for key, patterns := range versionPatterns {
+		if strings.HasPrefix(name, key) {
+			return patterns
+		}
+	}
+
+	var all []versionPattern
+	for _, patterns := range versionPatterns {
+		all = append(all, patterns...)
+	}
+	return all
+}
@@ -1,3 +1,10 @@
 module netscan
 
 go 1.24.3
+
+require (
+	golang.org/x/net v0.47.0
+	golang.org/x/time v0.12.0
+)
+
+require golang.org/x/sys v0.38.0 // indirect
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Durations are written to JSON as fractional milliseconds, under field
+// names ending in _ms, rather than as raw nanosecond counts
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func fromMillis(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+func (h HostResult) MarshalJSON() ([]byte, error) {
+	type plain HostResult
+	return json.Marshal(struct {
+		plain
+		Latency      float64 `json:"latency_ms"`
+		ScanDuration float64 `json:"scan_duration_ms"`
+	}{plain(h), millis(h.Latency), millis(h.ScanDuration)})
+}
+
+func (h *HostResult) UnmarshalJSON(data []byte) error {
+	type plain HostResult
+	aux := struct {
+		*plain
+		Latency      float64 `json:"latency_ms"`
+		ScanDuration float64 `json:"scan_duration_ms"`
+	}{plain: (*plain)(h)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	h.Latency = fromMillis(aux.Latency)
+	h.ScanDuration = fromMillis(aux.ScanDuration)
+	return nil
+}
+
+func (p PortResult) MarshalJSON() ([]byte, error) {
+	type plain PortResult
+	return json.Marshal(struct {
+		plain
+		Latency float64 `json:"latency_ms,omitempty"`
+	}{plain(p), millis(p.Latency)})
+}
+
+func (p *PortResult) UnmarshalJSON(data []byte) error {
+	type plain PortResult
+	aux := struct {
+		*plain
+		Latency float64 `json:"latency_ms"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Latency = fromMillis(aux.Latency)
+	return nil
+}
+
+func (h Hop) MarshalJSON() ([]byte, error) {
+	type plain Hop
+	return json.Marshal(struct {
+		plain
+		RTT float64 `json:"rtt_ms"`
+	}{plain(h), millis(h.RTT)})
+}
+
+func (h *Hop) UnmarshalJSON(data []byte) error {
+	type plain Hop
+	aux := struct {
+		*plain
+		RTT float64 `json:"rtt_ms"`
+	}{plain: (*plain)(h)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	h.RTT = fromMillis(aux.RTT)
+	return nil
+}
+
+func (r LatencyReport) MarshalJSON() ([]byte, error) {
+	type plain LatencyReport
+	return json.Marshal(struct {
+		plain
+		Min  float64 `json:"min_ms"`
+		Max  float64 `json:"max_ms"`
+		Mean float64 `json:"mean_ms"`
+		P50  float64 `json:"p50_ms"`
+		P95  float64 `json:"p95_ms"`
+		P99  float64 `json:"p99_ms"`
+	}{plain(r), millis(r.Min), millis(r.Max), millis(r.Mean), millis(r.P50), millis(r.P95), millis(r.P99)})
+}
+
+func (r ScanReport) MarshalJSON() ([]byte, error) {
+	type plain ScanReport
+	return json.Marshal(struct {
+		plain
+		Duration float64 `json:"duration_ms"`
+	}{plain(r), millis(r.Duration)})
+}
+
+func (r *ScanReport) UnmarshalJSON(data []byte) error {
+	type plain ScanReport
+	aux := struct {
+		*plain
+		Duration float64 `json:"duration_ms"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Duration = fromMillis(aux.Duration)
+	return nil
+}
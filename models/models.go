@@ -0,0 +1,317 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Port states. Closed ports answered the connection attempt with a reset,
+// filtered ports never answered at all, usually because a firewall dropped
+// the probe.
+//
+// UDP ports that stay silent get StateOpenFiltered instead, since a
+// service with nothing to say and a firewall dropping the probe look the
+// same. StateError means the probe never left this machine, e.g. because it
+// ran out of file descriptors, so nothing is known about the port; the
+// result's Error says why.
+const (
+	StateOpen         = "open"
+	StateClosed       = "closed"
+	StateFiltered     = "filtered"
+	StateOpenFiltered = "open|filtered"
+	StateError        = "error"
+)
+
+// Protocols a port can be scanned over
+const (
+	ProtoTCP = "tcp"
+	ProtoUDP = "udp"
+)
+
+type PortResult struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+	Open     bool   `json:"open"`
+	State    string `json:"state,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Banner   string `json:"banner,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Latency is how long an open port took to accept the connection. A
+	// port that's open but slow to accept often fronts an overloaded
+	// backend.
+	Latency time.Duration `json:"latency_ms,omitempty"`
+
+	// Version is the product and version the banner announces, e.g.
+	// "OpenSSH 8.9p1", when it's one banner.ParseVersion recognizes
+	Version string `json:"version,omitempty"`
+
+	// Unresponsive marks an open port whose service never sent anything,
+	// even after a probe, as opposed to one that answered with a banner
+	Unresponsive bool `json:"unresponsive,omitempty"`
+
+	// TLS describes the TLS handshake on ports that speak TLS, nil otherwise
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	// TLSOnly marks a service that only talks over TLS: a well-known TLS
+	// port, or one that gave nothing to a plaintext probe but completed a
+	// TLS handshake. Its banner came through the TLS connection.
+	TLSOnly bool `json:"tls_only,omitempty"`
+
+	// SecurityHeaders maps the HTTP security headers the service sent to
+	// their values when header auditing is on. Nil means the port wasn't
+	// audited or isn't HTTP; empty means none were sent.
+	SecurityHeaders map[string]string `json:"security_headers,omitempty"`
+
+	// HTTPStatus, HTTPServer and HTTPTitle fingerprint an HTTP service by
+	// its response to a request for /, after following one redirect on the
+	// same server: the status code, Server header and page title. HTTPStatus
+	// is zero if the port isn't HTTP.
+	HTTPStatus int    `json:"http_status,omitempty"`
+	HTTPServer string `json:"http_server,omitempty"`
+	HTTPTitle  string `json:"http_title,omitempty"`
+}
+
+// TLSInfo describes a TLS handshake with a port
+type TLSInfo struct {
+	// Completed is set when the handshake succeeded
+	Completed bool `json:"completed"`
+
+	// ClientCertRequested is set when the server asked for a client
+	// certificate during the handshake
+	ClientCertRequested bool `json:"client_cert_requested"`
+
+	// ClientCertAccepted is set when a client certificate was presented and
+	// the server completed the handshake with it
+	ClientCertAccepted bool `json:"client_cert_accepted"`
+
+	// Subject and Issuer name the server's certificate and its issuer by
+	// common name, or by full distinguished name if there's none
+	Subject string `json:"subject,omitempty"`
+	Issuer  string `json:"issuer,omitempty"`
+
+	// SANs lists the DNS names and IP addresses the certificate covers
+	SANs []string `json:"sans,omitempty"`
+
+	// NotAfter is when the certificate expires, zero if the handshake
+	// failed before the server sent one
+	NotAfter time.Time `json:"not_after"`
+}
+
+// SNMPResult is a community string an SNMP agent accepted, with the device
+// description it returned
+type SNMPResult struct {
+	Community string `json:"community"`
+	SysDescr  string `json:"sys_descr"`
+}
+
+// LatencyReport is the connect latency distribution of one service over
+// repeated probes. The durations are zero if every attempt failed.
+type LatencyReport struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Attempts int    `json:"attempts"`
+	Failures int    `json:"failures"`
+
+	Min  time.Duration `json:"min_ms"`
+	Max  time.Duration `json:"max_ms"`
+	Mean time.Duration `json:"mean_ms"`
+	P50  time.Duration `json:"p50_ms"`
+	P95  time.Duration `json:"p95_ms"`
+	P99  time.Duration `json:"p99_ms"`
+}
+
+// Hop is one step on the path to a host found by a traceroute: the router
+// whose address answered a probe with this TTL and how long it took. IP is
+// empty for a hop that never answered.
+type Hop struct {
+	TTL int           `json:"ttl"`
+	IP  string        `json:"ip,omitempty"`
+	RTT time.Duration `json:"rtt_ms"`
+}
+
+func (h Hop) String() string {
+	if h.IP == "" {
+		return fmt.Sprintf("%2d  *", h.TTL)
+	}
+	return fmt.Sprintf("%2d  %-15s  %v", h.TTL, h.IP, h.RTT.Round(time.Microsecond))
+}
+
+// Health verdicts
+const (
+	HealthUp       = "up"       // every expected port is open
+	HealthDegraded = "degraded" // some expected ports are open
+	HealthDown     = "down"     // no expected port is open
+)
+
+// HealthStatus is a one-line verdict on whether a host's expected ports
+// are open
+type HealthStatus struct {
+	Host    string `json:"host"`
+	Verdict string `json:"verdict"` // see the Health verdicts
+	Open    []int  `json:"open"`
+	Missing []int  `json:"missing"`
+}
+
+func (h HealthStatus) String() string {
+	if len(h.Missing) == 0 {
+		return fmt.Sprintf("%s %s", h.Host, h.Verdict)
+	}
+	missing := make([]string, len(h.Missing))
+	for i, port := range h.Missing {
+		missing[i] = strconv.Itoa(port)
+	}
+	return fmt.Sprintf("%s %s (missing %s)", h.Host, h.Verdict, strings.Join(missing, ", "))
+}
+
+// Host statuses give a complete accounting of every address in a range
+const (
+	StatusOpenPorts  = "alive"          // answered and has open ports
+	StatusNoPorts    = "alive-no-ports" // answered but none of the scanned ports are open
+	StatusNoResponse = "no-response"    // didn't answer the liveness probe
+	StatusExcluded   = "excluded"       // filtered out before scanning
+)
+
+type HostResult struct {
+	IP      string        `json:"ip"`
+	Alive   bool          `json:"alive"`
+	Ports   []PortResult  `json:"ports"`
+	Latency time.Duration `json:"latency_ms"`
+
+	// Status says why the host does or doesn't have results, see the
+	// Status constants
+	Status string `json:"status,omitempty"`
+
+	// PingError says why the liveness check found the host down, e.g. "no
+	// reply before the timeout" for one that's off or filtered as opposed
+	// to "every probe was refused" for one that's up with the probed
+	// ports closed. It's empty for hosts that answered.
+	PingError string `json:"ping_error,omitempty"`
+
+	// ScanDuration is how long liveness checking and scanning this host took
+	ScanDuration time.Duration `json:"scan_duration_ms"`
+
+	// PortsTruncated is set when more ports answered than the configured
+	// maximum and Ports only holds the first of them
+	PortsTruncated bool `json:"ports_truncated,omitempty"`
+
+	// SuspectedHoneypot is set when so many of the scanned ports were open
+	// that the host is probably a honeypot or a transparent proxy, and its
+	// open ports can't be taken at face value
+	SuspectedHoneypot bool `json:"suspected_honeypot,omitempty"`
+
+	// Hostname is the name the host goes by on the network, when a name
+	// probe found one
+	Hostname string `json:"hostname,omitempty"`
+
+	// Workgroup is the Windows workgroup or domain reported by NetBIOS
+	Workgroup string `json:"workgroup,omitempty"`
+
+	// MAC is the host's hardware address, when an ARP sweep found it
+	MAC string `json:"mac,omitempty"`
+
+	// Vendor is the maker of the host's network interface, looked up from
+	// the prefix of its MAC, e.g. "Cisco" or "VMware"
+	Vendor string `json:"vendor,omitempty"`
+
+	// OS is a coarse guess at the host's OS family from its TTL and
+	// banners, e.g. "Linux/Unix" or "Windows", when OS detection is on
+	OS string `json:"os,omitempty"`
+
+	// Advertised lists the service types the host announces over mDNS,
+	// e.g. "_http._tcp" or "_airplay._tcp"
+	Advertised []string `json:"advertised,omitempty"`
+
+	// Addresses lists every address of a host whose results were grouped
+	// from several, e.g. its IPv4 and IPv6 addresses. It's nil for a host
+	// scanned at a single address.
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// OpenCount returns how many of the host's ports are open
+func (h HostResult) OpenCount() int {
+	n := 0
+	for _, port := range h.Ports {
+		if port.Open {
+			n++
+		}
+	}
+	return n
+}
+
+// ScanReport describes a single scan run and its results
+type ScanReport struct {
+	Target   string        `json:"target"` // network or hosts that were scanned
+	Ports    []int         `json:"ports"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ms"`
+	Hosts    []HostResult  `json:"hosts"`
+
+	// Partial is set when the scan was cut short by a deadline or
+	// cancellation, so Hosts may be missing hosts or ports
+	Partial bool `json:"partial"`
+
+	// HostsTotal is how many addresses the scan set out to cover, and
+	// HostsScanned how many of them it finished. They differ only in a
+	// Partial report.
+	HostsTotal   int `json:"hosts_total"`
+	HostsScanned int `json:"hosts_scanned"`
+
+	// Unscanned lists the addresses the scan didn't finish in a Partial
+	// report, so a host missing from Hosts can be told apart from one that
+	// was never reached. Hosts cut off partway may still appear in Hosts
+	// with the ports scanned so far.
+	Unscanned []string `json:"unscanned,omitempty"`
+
+	// Version is the netscan version that produced the report
+	Version string `json:"version,omitempty"`
+
+	// Settings records the effective configuration the scan ran with, by
+	// setting name, so a saved report shows exactly how it was produced
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// Complete reports whether the scan ran to the end, so a host missing from
+// Hosts really wasn't found
+func (r ScanReport) Complete() bool {
+	return !r.Partial
+}
+
+// PortRanges returns the host's open ports in ascending order with
+// contiguous runs collapsed into ranges, e.g. ["22", "80", "8000-8010"]
+func (h HostResult) PortRanges() []string {
+	var open []int
+	for _, port := range h.Ports {
+		if port.Open {
+			open = append(open, port.Port)
+		}
+	}
+	return CollapsePorts(open)
+}
+
+// CollapsePorts returns ports in ascending order with contiguous runs
+// collapsed into ranges, e.g. ["22", "80", "8000-8010"]. ports itself is
+// left unsorted.
+func CollapsePorts(ports []int) []string {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+		if sorted[j] == sorted[i] {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+
+	return ranges
+}
@@ -0,0 +1,299 @@
+// Package monitor repeatedly checks a fixed set of hosts and ports and
+// reports their status.
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"netscan/scanner"
+	"netscan/utils"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often MonitorPorts checks its hosts
+const DefaultInterval = 30 * time.Second
+
+// MinInterval is the shortest time allowed between checks
+const MinInterval = time.Second
+
+// checkConcurrency is how many ports CheckHosts probes at once, across all
+// hosts
+const checkConcurrency = 100
+
+// Config controls a monitoring run
+type Config struct {
+	// FailThreshold is how many consecutive checks must find nothing open on
+	// a host before its ports are reported DOWN, so a momentary blip doesn't
+	// flip them. A threshold below 1 is treated as 1.
+	FailThreshold int
+
+	// Interval is the time between checks, at least MinInterval. An
+	// interval that isn't positive uses DefaultInterval.
+	Interval time.Duration
+
+	// StateFile, if set, keeps the snapshot of open ports between runs, so a
+	// restart only reports what changed while it was down instead of a
+	// fresh baseline
+	StateFile string
+
+	// Webhook, if set, is a URL each change is posted to as an Alert
+	Webhook string
+
+	// Influx, if set, is an InfluxDB write URL each check's open ports are
+	// posted to in line protocol, one point per open port as
+	// output.WriteInflux writes them, with InfluxToken as the API token
+	Influx      string
+	InfluxToken string
+
+	// Scanner, if set, makes every check's connections, so its exclusion
+	// list, proxy, rate limit, source port and timeouts apply to them. Nil
+	// uses scanner.DefaultConfig().
+	Scanner *scanner.Scanner
+
+	// Out is where status lines and changes are printed, os.Stdout if nil
+	Out io.Writer
+}
+
+// MonitorPorts checks hosts every DefaultInterval until the process exits.
+// A host is only reported DOWN after failThreshold consecutive failed
+// checks, so a momentary blip doesn't flip it; a threshold below 1 is
+// treated as 1.
+func MonitorPorts(hosts []string, ports []int, failThreshold int) {
+	MonitorPortsEvery(hosts, ports, failThreshold, DefaultInterval)
+}
+
+// MonitorPortsEvery is MonitorPorts with checks interval apart. An interval
+// that isn't positive uses DefaultInterval, and one shorter than
+// MinInterval is raised to it.
+func MonitorPortsEvery(hosts []string, ports []int, failThreshold int, interval time.Duration) {
+	if interval > 0 && interval < MinInterval {
+		interval = MinInterval
+	}
+	if err := Monitor(hosts, ports, Config{FailThreshold: failThreshold, Interval: interval}); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	}
+}
+
+// Monitor checks hosts as cfg says until the process exits. The first check
+// of each host prints its status; after that only changes are printed, one
+// line per port that opened or went DOWN, which is also posted to the
+// webhook if there is one. Invalid hosts are reported and left out, see
+// ValidateHosts, unless the Scanner uses a proxy, as are hosts on the
+// Scanner's exclusion list. It returns early only if the interval is shorter than
+// MinInterval, no host is valid or the state file can't be read.
+func Monitor(hosts []string, ports []int, cfg Config) error {
+	if cfg.FailThreshold < 1 {
+		cfg.FailThreshold = 1
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Interval < MinInterval {
+		return fmt.Errorf("interval %v is shorter than the minimum of %v", cfg.Interval, MinInterval)
+	}
+	if cfg.Scanner == nil {
+		cfg.Scanner = scanner.New(scanner.DefaultConfig())
+	}
+	out := cfg.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	var errs []error
+	if cfg.Scanner.Proxied() {
+		// Names may only resolve beyond the proxy, and looking them up here
+		// would send queries around it
+		hosts = uniqueHosts(hosts)
+	} else {
+		hosts, errs = ValidateHosts(hosts)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(out, "⚠️  Skipping %v\n", err)
+	}
+	hosts = slices.DeleteFunc(hosts, func(host string) bool {
+		if cfg.Scanner.Excluded(host) {
+			fmt.Fprintf(out, "⛔ %s is excluded from scanning\n", host)
+			return true
+		}
+		return false
+	})
+	if len(hosts) == 0 {
+		return errors.New("no valid hosts to monitor")
+	}
+
+	state := NewMonitorState()
+	if cfg.StateFile != "" {
+		var err error
+		if state, err = LoadState(cfg.StateFile); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\n👀 Monitoring %d hosts on %d ports (Ctrl+C to stop)\n", len(hosts), len(ports))
+	fmt.Fprintf(out, "⏰ Checking every %v, reporting changes only...\n", cfg.Interval)
+	if !state.Checked.IsZero() {
+		fmt.Fprintf(out, "📝 Comparing against the last check, at %s\n", state.Checked.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintln(out)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	failures := make(map[string]int)
+	for {
+		checked := time.Now()
+		results := checkHosts(cfg.Scanner, hosts, ports)
+		if cfg.Influx != "" {
+			sendInflux(out, cfg.Influx, cfg.InfluxToken, results, checked)
+		}
+		settled := report(out, hosts, results, state, failures, cfg.FailThreshold)
+		for _, change := range state.Diff(settled) {
+			marker := "🔴"
+			if change.Open {
+				marker = "🟢"
+			}
+			fmt.Fprintf(out, "%s %s %s\n", checked.Format("15:04:05"), marker, change)
+			if cfg.Webhook != "" {
+				sendAlert(out, cfg.Webhook, newAlert(change, checked))
+			}
+		}
+		state.Update(settled, checked)
+
+		if cfg.StateFile != "" {
+			if err := state.Save(cfg.StateFile); err != nil {
+				fmt.Fprintf(out, "⚠️  Couldn't save monitoring state: %v\n", err)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// ValidateHosts trims hosts and drops empty entries and duplicates, then
+// checks that each is an IP address or a name that resolves. It returns the
+// valid hosts in the order given, and an error naming each invalid one,
+// such as 192.168.1.300 or a typo'd hostname, which could never come up.
+func ValidateHosts(hosts []string) (valid []string, errs []error) {
+	for _, host := range uniqueHosts(hosts) {
+		if net.ParseIP(utils.BareHost(host)) != nil {
+			valid = append(valid, host)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+		_, err := net.DefaultResolver.LookupHost(ctx, host)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("host %q: not an IP address or a name that resolves", host))
+			continue
+		}
+		valid = append(valid, host)
+	}
+	return valid, errs
+}
+
+// lookupTimeout bounds ValidateHosts' lookup of each hostname
+const lookupTimeout = 2 * time.Second
+
+// uniqueHosts returns hosts trimmed, without empty entries or duplicates
+func uniqueHosts(hosts []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		unique = append(unique, host)
+	}
+	return unique
+}
+
+// CheckHosts scans ports on every host once and returns the open ports found
+// on each, keyed by the trimmed host name, in the order ports lists them.
+// Empty entries and duplicates are skipped.
+// Up to checkConcurrency ports are probed at once, across all hosts, so a
+// check takes about as long as the slowest port rather than all of them in
+// turn. Connections are made with the default configuration.
+func CheckHosts(hosts []string, ports []int) map[string][]int {
+	return checkHosts(scanner.New(scanner.DefaultConfig()), hosts, ports)
+}
+
+// checkHosts is CheckHosts, connecting with s
+func checkHosts(s *scanner.Scanner, hosts []string, ports []int) map[string][]int {
+	// open[host][i] is set when ports[i] is open on host. The map is filled
+	// before the probes start, so they only write their own elements.
+	open := make(map[string][]bool, len(hosts))
+	for _, host := range uniqueHosts(hosts) {
+		open[host] = make([]bool, len(ports))
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, checkConcurrency)
+	for host, found := range open {
+		for i, port := range ports {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				found[i] = s.ScanPort(host, port).Open
+			}()
+		}
+	}
+	wg.Wait()
+
+	results := make(map[string][]int, len(open))
+	for host, found := range open {
+		var openPorts []int
+		for i, port := range ports {
+			if found[i] {
+				openPorts = append(openPorts, port)
+			}
+		}
+		results[host] = openPorts
+	}
+
+	return results
+}
+
+// report prints the status of hosts checked for the first time to out,
+// updating the consecutive failure counts as it goes, and returns the
+// results that have settled: those of hosts with open ports, and of hosts
+// with none that have reached failThreshold. A host still short of the
+// threshold keeps its previous snapshot entry for now.
+func report(out io.Writer, hosts []string, results map[string][]int, state *MonitorState, failures map[string]int, failThreshold int) map[string][]int {
+	settled := make(map[string][]int, len(results))
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+
+		openPorts := results[host]
+		if len(openPorts) > 0 {
+			failures[host] = 0
+			settled[host] = openPorts
+			if !state.Known(host) {
+				fmt.Fprintf(out, "🔍 %s: 🟢 UP - Ports: %v\n", host, openPorts)
+			}
+			continue
+		}
+
+		failures[host]++
+		if failures[host] >= failThreshold {
+			settled[host] = nil
+			if !state.Known(host) {
+				fmt.Fprintf(out, "🔍 %s: 🔴 DOWN or filtered\n", host)
+			}
+		} else if !state.Known(host) || len(state.Ports[host]) > 0 {
+			fmt.Fprintf(out, "🔍 %s: 🟡 No response (%d/%d failed checks before DOWN)\n", host, failures[host], failThreshold)
+		}
+	}
+	return settled
+}
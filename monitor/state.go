@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MonitorState is a snapshot of the open ports on each monitored host, as
+// of the last check. Diffing each new check against it turns the stream of
+// statuses into a list of changes.
+type MonitorState struct {
+	// Ports holds each host's open ports, sorted. A host that's known but
+	// has none open maps to an empty list.
+	Ports map[string][]int `json:"ports"`
+
+	// Checked is when the snapshot was taken
+	Checked time.Time `json:"checked"`
+}
+
+// Change is a port that opened or closed on a host between two checks
+type Change struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	Open bool   `json:"open"`
+}
+
+func (c Change) String() string {
+	if c.Open {
+		return fmt.Sprintf("new port %d opened on %s", c.Port, c.Host)
+	}
+	return fmt.Sprintf("port %d on %s went DOWN", c.Port, c.Host)
+}
+
+// NewMonitorState returns an empty snapshot, which knows no hosts
+func NewMonitorState() *MonitorState {
+	return &MonitorState{Ports: make(map[string][]int)}
+}
+
+// LoadState reads a snapshot saved by Save. A missing file gives an empty
+// snapshot, as on the first run.
+func LoadState(path string) (*MonitorState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewMonitorState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := NewMonitorState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if state.Ports == nil {
+		state.Ports = make(map[string][]int)
+	}
+	return state, nil
+}
+
+// Save writes the snapshot to path as JSON. The file is replaced in one
+// step, so a crash mid-write leaves the previous snapshot intact.
+func (s *MonitorState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Known reports whether the snapshot has an entry for host
+func (s *MonitorState) Known(host string) bool {
+	_, ok := s.Ports[host]
+	return ok
+}
+
+// Diff returns the ports that opened or closed on each host in current,
+// the result of CheckHosts, since the snapshot was taken, sorted by host
+// and port. Hosts the snapshot doesn't know have no changes: their ports
+// are a baseline, not news. The snapshot itself is left as is.
+func (s *MonitorState) Diff(current map[string][]int) []Change {
+	var changes []Change
+	for host, ports := range current {
+		before, ok := s.Ports[host]
+		if !ok {
+			continue
+		}
+		for _, port := range ports {
+			if !slices.Contains(before, port) {
+				changes = append(changes, Change{Host: host, Port: port, Open: true})
+			}
+		}
+		for _, port := range before {
+			if !slices.Contains(ports, port) {
+				changes = append(changes, Change{Host: host, Port: port})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Host != changes[j].Host {
+			return changes[i].Host < changes[j].Host
+		}
+		return changes[i].Port < changes[j].Port
+	})
+	return changes
+}
+
+// Update records current, the result of CheckHosts taken at checked, as
+// the snapshot. Hosts missing from current keep their previous entry.
+func (s *MonitorState) Update(current map[string][]int, checked time.Time) {
+	for host, ports := range current {
+		ports = slices.Clone(ports)
+		if ports == nil {
+			ports = []int{}
+		}
+		slices.Sort(ports)
+		s.Ports[host] = ports
+	}
+	s.Checked = checked
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"netscan/models"
+	"netscan/output"
+	"sort"
+	"time"
+)
+
+// States reported in alerts
+const (
+	alertOpen = "open"
+	alertDown = "down"
+)
+
+// webhookClient posts alerts. Its short timeout bounds how long a slow
+// endpoint can hold on to an alert's goroutine.
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// Alert is the JSON payload posted to a webhook for a port that changed
+// state. The states are "open" or "down".
+type Alert struct {
+	Host      string    `json:"host"`
+	Port      int       `json:"port"`
+	OldState  string    `json:"old_state"`
+	NewState  string    `json:"new_state"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// newAlert describes change, seen at the check made at checked
+func newAlert(change Change, checked time.Time) Alert {
+	alert := Alert{Host: change.Host, Port: change.Port, OldState: alertOpen, NewState: alertDown, Timestamp: checked}
+	if change.Open {
+		alert.OldState, alert.NewState = alertDown, alertOpen
+	}
+	return alert
+}
+
+// sendAlert posts alert to url in the background, so a slow endpoint never
+// holds up the next check. A failure is printed to out and the alert
+// dropped.
+func sendAlert(out io.Writer, url string, alert Alert) {
+	go func() {
+		if err := postAlert(url, alert); err != nil {
+			fmt.Fprintf(out, "⚠️  Webhook alert for port %d on %s failed: %v\n", alert.Port, alert.Host, err)
+		}
+	}()
+}
+
+// postAlert posts alert to url as JSON, retrying once if the server
+// answers with a 5xx status
+func postAlert(url string, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode >= 500 && attempt == 0:
+			continue
+		default:
+			return fmt.Errorf("%s", resp.Status)
+		}
+	}
+}
+
+// sendInflux posts the open ports one check found, keyed by host as from
+// CheckHosts, to an InfluxDB write URL in the background, like sendAlert. A
+// failure is printed to out and the points dropped.
+func sendInflux(out io.Writer, url, token string, results map[string][]int, checked time.Time) {
+	var hosts []models.HostResult
+	for host, open := range results {
+		result := models.HostResult{IP: host, Alive: len(open) > 0}
+		for _, port := range open {
+			result.Ports = append(result.Ports, models.PortResult{Port: port, Open: true, State: models.StateOpen})
+		}
+		hosts = append(hosts, result)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].IP < hosts[j].IP })
+
+	go func() {
+		if err := output.PostInflux(url, token, hosts, checked); err != nil {
+			fmt.Fprintf(out, "⚠️  InfluxDB write failed: %v\n", err)
+		}
+	}()
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"netscan/models"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WriteCSV writes one CSV row per open port, with the columns ip, hostname,
+// port, protocol, service, banner and latency_ms after a header row, for
+// importing into spreadsheets. latency_ms is how long the port took to
+// accept the connection, or the host's ping latency where that wasn't
+// measured. Banners are flattened onto a single line.
+func WriteCSV(w io.Writer, hosts []models.HostResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ip", "hostname", "port", "protocol", "service", "banner", "latency_ms"})
+
+	for _, host := range hosts {
+		for _, port := range host.Ports {
+			if !port.Open {
+				continue
+			}
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = models.ProtoTCP
+			}
+			cw.Write([]string{
+				host.IP,
+				host.Hostname,
+				strconv.Itoa(port.Port),
+				protocol,
+				port.Service,
+				csvCell(port.Banner),
+				latencyMS(portLatency(host, port)),
+			})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSweepCSV writes one CSV row per host of a ping sweep, with the
+// columns ip, alive and latency_ms after a header row
+func WriteSweepCSV(w io.Writer, hosts []models.HostResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ip", "alive", "latency_ms"})
+
+	for _, host := range hosts {
+		cw.Write([]string{host.IP, strconv.FormatBool(host.Alive), latencyMS(host.Latency)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// latencyMS formats d in milliseconds, the unit of the latency_ms columns
+func latencyMS(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// portLatency returns how long port took to accept a connection, falling
+// back to host's ping latency for ports where that wasn't measured, such
+// as UDP ones
+func portLatency(host models.HostResult, port models.PortResult) time.Duration {
+	if port.Latency > 0 {
+		return port.Latency
+	}
+	return host.Latency
+}
+
+// csvCell flattens s onto a single line, turning line breaks and other
+// control characters into spaces, so a spreadsheet shows it in one cell
+func csvCell(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
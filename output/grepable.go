@@ -0,0 +1,54 @@
+package output
+
+import (
+	"netscan/models"
+	"strconv"
+	"strings"
+)
+
+// FormatGrepable formats hosts one per line in the style of nmap's -oG
+// output, for grep and awk pipelines:
+//
+//	Host: 10.0.0.5 (db01)	Ports: 22/open/ssh, 443/open/https
+//
+// Each port is port/state/service, with the service lowercased and empty
+// when unknown. A host without ports gets a Status field (Up or Down)
+// instead. Fields are tab-separated and come in a fixed order.
+func FormatGrepable(hosts []models.HostResult) string {
+	var b strings.Builder
+	for _, host := range hosts {
+		b.WriteString("Host: " + host.IP + " (" + grepableField(host.Hostname) + ")\t")
+		if len(host.Ports) == 0 {
+			status := "Down"
+			if host.Alive {
+				status = "Up"
+			}
+			b.WriteString("Status: " + status + "\n")
+			continue
+		}
+
+		entries := make([]string, 0, len(host.Ports))
+		for _, port := range host.Ports {
+			state := port.State
+			if state == "" && port.Open {
+				state = models.StateOpen
+			}
+			entries = append(entries, strconv.Itoa(port.Port)+"/"+state+"/"+
+				grepableField(strings.ToLower(port.Service)))
+		}
+		b.WriteString("Ports: " + strings.Join(entries, ", ") + "\n")
+	}
+	return b.String()
+}
+
+// grepableField keeps s from breaking up the fields around it, replacing
+// the separators and whitespace it contains
+func grepableField(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', ',', '(', ')', ' ', '\t', '\n', '\r':
+			return '-'
+		}
+		return r
+	}, s)
+}
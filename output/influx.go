@@ -0,0 +1,84 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"netscan/models"
+	"strings"
+	"time"
+)
+
+// WriteInflux writes one InfluxDB line-protocol point per port, measurement
+// netscan_port tagged with host, port and service, with the port state and
+// latency as fields: the time to connect to the port where it was measured,
+// the host's ping latency otherwise. Every point is stamped with ts.
+func WriteInflux(w io.Writer, hosts []models.HostResult, ts time.Time) error {
+	ew := &errWriter{w: w}
+
+	for _, host := range hosts {
+		for _, port := range host.Ports {
+			state := port.State
+			if state == "" && port.Open {
+				state = models.StateOpen
+			}
+			open := 0
+			if port.Open {
+				open = 1
+			}
+
+			ew.printf("netscan_port,host=%s,port=%d", influxTag(host.IP), port.Port)
+			if port.Service != "" {
+				ew.printf(",service=%s", influxTag(port.Service))
+			}
+			ew.printf(" state=%s,open=%di,latency_ms=%s %d\n",
+				influxString(state), open,
+				latencyMS(portLatency(host, port)),
+				ts.UnixNano())
+		}
+	}
+
+	return ew.err
+}
+
+// PostInflux sends the points written by WriteInflux to an InfluxDB write
+// endpoint such as http://influx:8086/write?db=netscan (1.x) or
+// http://influx:8086/api/v2/write?org=o&bucket=netscan (2.x). token is sent
+// as an API token when non-empty.
+func PostInflux(url, token string, hosts []models.HostResult, ts time.Time) error {
+	var body bytes.Buffer
+	if err := WriteInflux(&body, hosts, ts); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx write failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// influxTag escapes a tag value for line protocol
+var influxTag = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace
+
+// influxString quotes a string field value for line protocol
+func influxString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,147 @@
+// Package output renders scan results in formats meant for files and other
+// tools rather than the interactive console.
+package output
+
+import (
+	"fmt"
+	"io"
+	"netscan/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown writes report as a Markdown document: a summary table of
+// every host followed by a section per host listing its ports, services and
+// banners
+func WriteMarkdown(w io.Writer, report models.ScanReport) error {
+	mw := &errWriter{w: w}
+
+	mw.printf("# Scan report: %s\n\n", mdEscape(report.Target))
+	mw.printf("- **Started:** %s\n", report.Start.Format("2006-01-02 15:04:05 MST"))
+	mw.printf("- **Duration:** %v\n", report.Duration.Round(time.Millisecond))
+	mw.printf("- **Ports scanned:** %d\n", len(report.Ports))
+	mw.printf("- **Hosts reported:** %d\n\n", len(report.Hosts))
+	if report.Partial {
+		mw.printf("> **Partial results:** the scan was stopped before it finished, after %d of %d hosts. Hosts it didn't reach are missing from this report.\n\n",
+			report.HostsScanned, report.HostsTotal)
+	}
+	writeSettings(mw, report)
+
+	mw.printf("## Summary\n\n")
+	mw.printf("| Host | Status | Open ports | Services | Scan time |\n")
+	mw.printf("|------|--------|-----------:|----------|----------:|\n")
+	for _, host := range report.Hosts {
+		status := host.Status
+		if status == "" {
+			status = "alive"
+		}
+		mw.printf("| %s | %s | %d | %s | %v |\n", mdEscape(host.IP), status, host.OpenCount(),
+			mdEscape(strings.Join(services(host), ", ")), host.ScanDuration.Round(time.Millisecond))
+	}
+	mw.printf("\n")
+
+	for _, host := range report.Hosts {
+		if len(host.Ports) == 0 {
+			continue
+		}
+
+		ports := append([]models.PortResult(nil), host.Ports...)
+		sort.Slice(ports, func(i, j int) bool {
+			return ports[i].Port < ports[j].Port
+		})
+
+		mw.printf("## %s\n\n", mdEscape(host.IP))
+		if len(host.Addresses) > 1 {
+			mw.printf("Addresses: %s\n\n", mdEscape(strings.Join(host.Addresses, ", ")))
+		}
+		if host.PortsTruncated {
+			mw.printf("> **Note:** all/many ports open (likely honeypot or tarpit), only the first %d are listed.\n\n", len(ports))
+		}
+		if host.SuspectedHoneypot {
+			mw.printf("> **Suspected honeypot:** nearly every scanned port is open, so these results are unreliable.\n\n")
+		}
+		mw.printf("| Port | State | Service | Banner |\n")
+		mw.printf("|-----:|-------|---------|--------|\n")
+		for _, port := range ports {
+			service := port.Service
+			if service == "" {
+				service = "Unknown"
+			}
+			state := port.State
+			if state == "" && port.Open {
+				state = models.StateOpen
+			}
+			if port.Unresponsive {
+				state += " (no service response)"
+			}
+			banner := ""
+			if port.Banner != "" {
+				banner = "`" + strings.ReplaceAll(port.Banner, "`", "'") + "`"
+			}
+			mw.printf("| %d | %s | %s | %s |\n", port.Port, state, mdEscape(service), mdEscape(banner))
+		}
+		mw.printf("\n")
+	}
+
+	return mw.err
+}
+
+// services lists the distinct service names of a host's open ports
+func services(host models.HostResult) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, port := range host.Ports {
+		if !port.Open || port.Service == "" || seen[port.Service] {
+			continue
+		}
+		seen[port.Service] = true
+		names = append(names, port.Service)
+	}
+	return names
+}
+
+// mdEscape keeps arbitrary text from breaking a Markdown table row
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// errWriter remembers the first write error so formatters can check once at
+// the end instead of after every line
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
+
+// writeSettings lists what was scanned and how, so the report can be
+// reproduced. Reports without recorded settings get just the port list.
+func writeSettings(mw *errWriter, report models.ScanReport) {
+	mw.printf("## Scan settings\n\n")
+	if report.Version != "" {
+		mw.printf("- **netscan version:** %s\n", mdEscape(report.Version))
+	}
+	mw.printf("- **Ports:** %s\n", mdEscape(strings.Join(models.CollapsePorts(report.Ports), ",")))
+
+	names := make([]string, 0, len(report.Settings))
+	for name := range report.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := report.Settings[name]
+		if value == "" {
+			value = "(none)"
+		}
+		mw.printf("- **%s:** %s\n", name, mdEscape(value))
+	}
+	mw.printf("\n")
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"netscan/models"
+)
+
+// WriteNDJSON writes hosts as newline-delimited JSON, one compact object per
+// host per line, for log pipelines such as Loki or the Elasticsearch bulk
+// API. Hosts are written in the order given.
+func WriteNDJSON(w io.Writer, hosts []models.HostResult) error {
+	enc := json.NewEncoder(w)
+	for _, host := range hosts {
+		// Encode terminates each object with a newline
+		if err := enc.Encode(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadNDJSON reads hosts written by WriteNDJSON, such as a saved scan to
+// compare against a later one
+func ReadNDJSON(r io.Reader) ([]models.HostResult, error) {
+	var hosts []models.HostResult
+	dec := json.NewDecoder(r)
+	for {
+		var host models.HostResult
+		if err := dec.Decode(&host); err == io.EOF {
+			return hosts, nil
+		} else if err != nil {
+			return hosts, err
+		}
+		hosts = append(hosts, host)
+	}
+}
+
+// ReadHosts reads saved hosts in either form netscan writes them: a JSON
+// array, as -format json prints, or NDJSON
+func ReadHosts(r io.Reader) ([]models.HostResult, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		br.UnreadByte()
+		if b != '[' {
+			return ReadNDJSON(br)
+		}
+		var hosts []models.HostResult
+		err = json.NewDecoder(br).Decode(&hosts)
+		return hosts, err
+	}
+}
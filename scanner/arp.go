@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+	"time"
+)
+
+// ErrARPUnavailable is returned when an ARP sweep can't be run: the network
+// isn't directly attached, the platform has no raw link-layer sockets, or
+// the process lacks the privileges to open one
+var ErrARPUnavailable = errors.New("ARP unavailable")
+
+// arpReply is the first ARP reply from a host: its MAC address and how long
+// after the request it arrived
+type arpReply struct {
+	mac net.HardwareAddr
+	rtt time.Duration
+}
+
+// ARPScan sweeps network with ARP using the default configuration
+func ARPScan(iface, network string) ([]models.HostResult, error) {
+	return New(DefaultConfig()).ARPScan(iface, network)
+}
+
+// ARPScan finds live hosts on network by broadcasting an ARP request for
+// each address from iface and recording the MAC address of every host that
+// replies. Hosts answer ARP even with every port filtered, so it finds
+// devices a TCP ping misses. An empty iface picks the interface with an
+// address on network.
+//
+// ARP only reaches hosts on the same layer 2 segment, so network must be an
+// IPv4 subnet directly attached to the interface. It needs a raw packet
+// socket (root or CAP_NET_RAW) and is only implemented on Linux. When it
+// can't be used for any of these reasons, ARPScan says why and falls back
+// to PingSweep. An invalid network or an unknown iface is an error.
+func (s *Scanner) ARPScan(iface, network string) ([]models.HostResult, error) {
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		return nil, err
+	}
+	ifi, src, err := arpInterface(iface, ips)
+	if err == nil {
+		var hosts []models.HostResult
+		hosts, err = s.arpScan(context.Background(), ifi, src, network, ips)
+		if err == nil {
+			return hosts, nil
+		}
+	}
+	if !errors.Is(err, ErrARPUnavailable) {
+		return nil, err
+	}
+
+	fmt.Fprintf(s.out, "⚠️  %v, falling back to a ping sweep\n", err)
+	return s.PingSweep(network), nil
+}
+
+// arpScan is ARPScan over ifi, sending from src
+func (s *Scanner) arpScan(ctx context.Context, ifi *net.Interface, src net.IP, network string, ips []string) ([]models.HostResult, error) {
+	fmt.Fprintf(s.out, "\n🔍 ARP sweep of %s on %s\n", network, ifi.Name)
+
+	ips = utils.ExcludeIPs(ips, s.cfg.Exclude)
+	var targets []net.IP
+	for _, ip := range scanOrder(s, ips) {
+		targets = append(targets, net.ParseIP(ip).To4())
+	}
+
+	start := time.Now()
+	replies, err := s.arpSweep(ctx, ifi, src, targets, 2*s.pingTimeout())
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	hosts := make([]models.HostResult, 0, len(replies))
+	for ip, reply := range replies {
+		host := models.HostResult{
+			IP:      ip,
+			Alive:   true,
+			Latency: reply.rtt,
+			MAC:     reply.mac.String(),
+			Vendor:  LookupVendor(reply.mac.String()),
+		}
+		s.resolvePTR(ctx, &host)
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return utils.CompareIPs(hosts[i].IP, hosts[j].IP)
+	})
+
+	fmt.Fprintf(s.out, "\n✅ ARP sweep completed in %v\n", elapsed)
+	fmt.Fprintf(s.out, "📊 Found %d live hosts out of %d scanned:\n\n", len(hosts), len(ips))
+	s.printLive(hosts)
+
+	return hosts, nil
+}
+
+// arpInterface returns the interface to sweep ips from and its IPv4
+// address, which must be on a subnet holding every one of ips. name picks
+// the interface; an empty name searches every interface that's up.
+func arpInterface(name string, ips []string) (*net.Interface, net.IP, error) {
+	var candidates []net.Interface
+	if name != "" {
+		ifi, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = []net.Interface{*ifi}
+	} else {
+		all, err := net.Interfaces()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, ifi := range all {
+			if ifi.Flags&net.FlagUp != 0 && ifi.Flags&net.FlagLoopback == 0 {
+				candidates = append(candidates, ifi)
+			}
+		}
+	}
+
+	for i := range candidates {
+		ifi := &candidates[i]
+		if len(ifi.HardwareAddr) != 6 {
+			continue
+		}
+		addrs, err := ifi.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			subnet, ok := addr.(*net.IPNet)
+			if ok && subnet.IP.To4() != nil && containsAll(subnet, ips) {
+				return ifi, subnet.IP.To4(), nil
+			}
+		}
+	}
+
+	if name != "" {
+		return nil, nil, fmt.Errorf("%w: the network isn't directly attached to %s", ErrARPUnavailable, name)
+	}
+	return nil, nil, fmt.Errorf("%w: the network isn't directly attached to any Ethernet interface", ErrARPUnavailable)
+}
+
+// containsAll reports whether subnet holds every one of ips, which are
+// IPv4 addresses in ascending order
+func containsAll(subnet *net.IPNet, ips []string) bool {
+	if len(ips) == 0 {
+		return false
+	}
+	first, last := net.ParseIP(ips[0]), net.ParseIP(ips[len(ips)-1])
+	return first.To4() != nil && subnet.Contains(first) && subnet.Contains(last)
+}
@@ -0,0 +1,158 @@
+//go:build linux
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Ethernet and ARP constants (RFC 826)
+const (
+	etherTypeARP = 0x0806
+	arpRequest   = 1
+	arpReplyOp   = 2
+	arpFrameLen  = 42 // Ethernet header plus an ARP packet for IPv4
+	minFrameLen  = 60 // shortest Ethernet frame, less the FCS
+)
+
+// arpSweep broadcasts an ARP request for each of targets from ifi, as src,
+// and collects replies until wait has passed since the last request or
+// every target has answered. Replies are keyed by IP address.
+func (s *Scanner) arpSweep(ctx context.Context, ifi *net.Interface, src net.IP, targets []net.IP, wait time.Duration) (map[string]arpReply, error) {
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(htons(etherTypeARP)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrARPUnavailable, err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(etherTypeARP), Ifindex: ifi.Index}); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	// A non-blocking descriptor gets the runtime poller, so reads honour
+	// deadlines
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	conn := os.NewFile(uintptr(fd), "arp")
+	defer conn.Close()
+
+	wanted := make(map[string]bool, len(targets))
+	for _, ip := range targets {
+		wanted[ip.String()] = true
+	}
+
+	var (
+		mu      sync.Mutex
+		sent    = make(map[string]time.Time, len(targets))
+		replies = make(map[string]arpReply)
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1500)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			ip, mac, ok := parseARPReply(buf[:n])
+			if !ok || !wanted[ip] {
+				continue
+			}
+			mu.Lock()
+			if _, seen := replies[ip]; !seen {
+				replies[ip] = arpReply{mac: mac, rtt: time.Since(sent[ip])}
+			}
+			all := len(replies) == len(targets)
+			mu.Unlock()
+			if all {
+				return
+			}
+		}
+	}()
+
+	var sendErr error
+	for _, ip := range targets {
+		s.waitWhilePaused(ctx)
+		if err := s.waitRate(ctx); err != nil {
+			sendErr = err
+			break
+		}
+		mu.Lock()
+		sent[ip.String()] = time.Now()
+		mu.Unlock()
+		if _, err := conn.Write(arpFrame(ifi.HardwareAddr, src, ip)); err != nil {
+			sendErr = err
+			break
+		}
+	}
+	// Stragglers get wait after the last request to answer
+	if sendErr != nil {
+		conn.SetReadDeadline(time.Now())
+	} else {
+		conn.SetReadDeadline(time.Now().Add(wait))
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		conn.SetReadDeadline(time.Now())
+		<-done
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sendErr != nil && len(replies) == 0 && !errors.Is(sendErr, ctx.Err()) {
+		return nil, sendErr
+	}
+	return replies, nil
+}
+
+// arpFrame builds a broadcast Ethernet frame holding an ARP request from
+// mac and src for target
+func arpFrame(mac net.HardwareAddr, src, target net.IP) []byte {
+	frame := make([]byte, minFrameLen)
+	copy(frame[0:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], etherTypeARP)
+
+	arp := frame[14:]
+	binary.BigEndian.PutUint16(arp[0:2], 1)      // hardware type: Ethernet
+	binary.BigEndian.PutUint16(arp[2:4], 0x0800) // protocol type: IPv4
+	arp[4], arp[5] = 6, 4                        // address lengths
+	binary.BigEndian.PutUint16(arp[6:8], arpRequest)
+	copy(arp[8:14], mac)
+	copy(arp[14:18], src.To4())
+	// The target hardware address stays zero, it's what's being asked for
+	copy(arp[24:28], target.To4())
+	return frame
+}
+
+// parseARPReply returns the sender of an ARP reply frame, or false if the
+// frame isn't one
+func parseARPReply(frame []byte) (ip string, mac net.HardwareAddr, ok bool) {
+	if len(frame) < arpFrameLen || binary.BigEndian.Uint16(frame[12:14]) != etherTypeARP {
+		return "", nil, false
+	}
+	arp := frame[14:]
+	if !bytes.Equal(arp[0:6], []byte{0, 1, 0x08, 0x00, 6, 4}) || binary.BigEndian.Uint16(arp[6:8]) != arpReplyOp {
+		return "", nil, false
+	}
+	mac = append(net.HardwareAddr(nil), arp[8:14]...)
+	return net.IP(arp[14:18]).String(), mac, true
+}
+
+// htons converts a 16-bit value to network byte order, as the packet
+// socket calls expect
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
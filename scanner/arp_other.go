@@ -0,0 +1,16 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"time"
+)
+
+// arpSweep needs raw packet sockets, which only Linux provides here
+func (s *Scanner) arpSweep(_ context.Context, _ *net.Interface, _ net.IP, _ []net.IP, _ time.Duration) (map[string]arpReply, error) {
+	return nil, fmt.Errorf("%w: ARP sweeps aren't supported on %s", ErrARPUnavailable, runtime.GOOS)
+}
@@ -0,0 +1,27 @@
+package scanner
+
+import "sync"
+
+// ResultCollector gathers results from concurrent scan goroutines. The zero
+// value is ready to use.
+type ResultCollector[T any] struct {
+	mu      sync.Mutex
+	results []T
+}
+
+// Add appends results to the collection
+func (c *ResultCollector[T]) Add(results ...T) {
+	c.mu.Lock()
+	c.results = append(c.results, results...)
+	c.mu.Unlock()
+}
+
+// Snapshot returns a copy of everything collected so far
+func (c *ResultCollector[T]) Snapshot() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]T, len(c.results))
+	copy(out, c.results)
+	return out
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// timeoutError is a net.Error that timed out, as a dial to a filtered port
+// does
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// fakeDialer fails its first len(errs) dials with errs in turn, then
+// succeeds, counting every attempt
+type fakeDialer struct {
+	errs  []error
+	calls int
+}
+
+func (d *fakeDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	d.calls++
+	if d.calls <= len(d.errs) {
+		return nil, d.errs[d.calls-1]
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func TestDialRetryingRetriesTimeouts(t *testing.T) {
+	d := &fakeDialer{errs: []error{&net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}}}
+	s := New(ScanConfig{Dialer: d, Retries: 2})
+
+	conn, _, err := s.dialRetrying(context.Background(), "192.0.2.1:80", time.Second)
+	if err != nil {
+		t.Fatalf("dialRetrying: %v, want success after a retry", err)
+	}
+	conn.Close()
+	if d.calls != 2 {
+		t.Errorf("dialed %d times, want 2", d.calls)
+	}
+}
+
+func TestDialRetryingGivesUpAfterRetries(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}
+	d := &fakeDialer{errs: []error{timeout, timeout, timeout}}
+	s := New(ScanConfig{Dialer: d, Retries: 1})
+
+	if _, _, err := s.dialRetrying(context.Background(), "192.0.2.1:80", time.Second); !isTimeout(err) {
+		t.Fatalf("dialRetrying: %v, want a timeout", err)
+	}
+	if d.calls != 2 {
+		t.Errorf("dialed %d times, want 2", d.calls)
+	}
+}
+
+func TestDialRetryingDoesNotRetryRefused(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	d := &fakeDialer{errs: []error{refused}}
+	s := New(ScanConfig{Dialer: d, Retries: 2})
+
+	_, _, err := s.dialRetrying(context.Background(), "192.0.2.1:80", time.Second)
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("dialRetrying: %v, want connection refused", err)
+	}
+	if d.calls != 1 {
+		t.Errorf("dialed %d times, want 1", d.calls)
+	}
+}
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanWithDeadline scans ports on every target (anything NormalizeTargets
+// accepts, with hostnames resolved by cfg.Resolver) and returns the results
+// as a report without printing anything. The scan stops when ctx is done or
+// cfg.Deadline passes, whichever comes first: no new hosts or ports are
+// started, dials in flight are abandoned, and the report holds whatever was
+// collected by then, marked Partial, together with the context's error. The
+// report's Unscanned field lists the hosts that weren't finished. Banner
+// grabs already under way can delay the return by up to their timeout.
+//
+// Only live hosts are reported unless cfg.Detailed is set. Invalid targets
+// are an error before any scanning starts.
+func ScanWithDeadline(ctx context.Context, cfg ScanConfig, targets []string, ports []int) (models.ScanReport, error) {
+	if !cfg.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, cfg.Deadline)
+		defer cancel()
+	}
+
+	s := New(cfg)
+	report := models.ScanReport{
+		Target:   strings.Join(targets, ", "),
+		Ports:    ports,
+		Start:    time.Now(),
+		Version:  Version(),
+		Settings: s.Settings(),
+	}
+
+	hosts, errs := utils.NormalizeTargetsWith(targets, func(host string) ([]net.IP, error) {
+		return s.resolver().LookupIP(ctx, "ip", host)
+	})
+	if len(errs) > 0 {
+		return report, errors.Join(errs...)
+	}
+
+	report.HostsTotal = len(hosts)
+
+	var collector ResultCollector[models.HostResult]
+	var doneMu sync.Mutex
+	done := make(map[string]bool, len(hosts))
+	var wg sync.WaitGroup
+	maxHostConcurrency, _ := s.discoveryConcurrency()
+	sem := make(chan struct{}, maxHostConcurrency)
+
+dispatch:
+	for _, host := range hosts {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := s.scanHost(ctx, host, ports)
+			if result.Alive || cfg.Detailed {
+				collector.Add(result)
+			}
+			if ctx.Err() == nil {
+				doneMu.Lock()
+				done[host] = true
+				doneMu.Unlock()
+			}
+		}(host)
+	}
+	wg.Wait()
+
+	report.Hosts = collector.Snapshot()
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		return utils.CompareIPs(report.Hosts[i].IP, report.Hosts[j].IP)
+	})
+	report.Duration = time.Since(report.Start)
+	report.HostsScanned = len(done)
+
+	if err := ctx.Err(); err != nil {
+		report.Partial = true
+		for _, host := range hosts {
+			if !done[host] {
+				report.Unscanned = append(report.Unscanned, host)
+			}
+		}
+		return report, err
+	}
+	return report, nil
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Dialer opens the connections a scan makes, TCP and UDP alike. Set
+// ScanConfig.Dialer to route them through something other than the local
+// network stack, such as a SOCKS proxy, or to fake them in tests with
+// canned opens, refusals and timeouts per port.
+type Dialer interface {
+	DialTimeout(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// ContextDialer is a Dialer that can also abandon a dial when a context
+// ends. A Scanner uses DialContext on Dialers that have it, bounded by the
+// dial timeout, so that cancelled scans stop promptly.
+type ContextDialer interface {
+	Dialer
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DialerFunc adapts a function to the Dialer interface
+type DialerFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// DialTimeout calls f
+func (f DialerFunc) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return f(network, address, timeout)
+}
+
+// dialWith dials address with the configured Dialer, after applying the
+// exclusion list to it since the Dialer won't
+func (s *Scanner) dialWith(ctx context.Context, network, address string, timeout time.Duration) (net.Conn, error) {
+	if err := s.checkTarget(ctx, address); err != nil {
+		return nil, err
+	}
+	return s.dialDirect(ctx, network, address, timeout)
+}
+
+// dialDirect dials address with the configured Dialer, or the network
+// stack if there is none, without any exclusion checks
+func (s *Scanner) dialDirect(ctx context.Context, network, address string, timeout time.Duration) (net.Conn, error) {
+	switch d := s.cfg.Dialer.(type) {
+	case nil:
+		nd := net.Dialer{Timeout: timeout, Resolver: s.resolver()}
+		return nd.DialContext(ctx, network, address)
+	case ContextDialer:
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return d.DialContext(ctx, network, address)
+	default:
+		return d.DialTimeout(network, address, timeout)
+	}
+}
@@ -2,90 +2,212 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
-
+	"netscan/banner"
+	"netscan/models"
+	"netscan/utils"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type PortResult struct {
-	Port    int
-	Open    bool
-	Service string
-	Banner  string
+// CommonServices names the services usually found on well-known TCP ports.
+// LookupService falls back to it for ports the table loaded with
+// LoadServicesFromFile doesn't name.
+var CommonServices = map[int]string{
+	20:    "FTP-Data",
+	21:    "FTP",
+	22:    "SSH",
+	23:    "Telnet",
+	25:    "SMTP",
+	53:    "DNS",
+	80:    "HTTP",
+	88:    "Kerberos",
+	110:   "POP3",
+	111:   "RPCbind",
+	119:   "NNTP",
+	135:   "RPC",
+	139:   "NetBIOS",
+	143:   "IMAP",
+	179:   "BGP",
+	389:   "LDAP",
+	443:   "HTTPS",
+	445:   "SMB",
+	465:   "SMTPS",
+	515:   "LPD",
+	548:   "AFP",
+	554:   "RTSP",
+	587:   "Submission",
+	631:   "IPP",
+	636:   "LDAPS",
+	873:   "rsync",
+	993:   "IMAPS",
+	995:   "POP3S",
+	1080:  "SOCKS",
+	1194:  "OpenVPN",
+	1433:  "MSSQL",
+	1521:  "Oracle",
+	1723:  "PPTP",
+	1883:  "MQTT",
+	2049:  "NFS",
+	2181:  "ZooKeeper",
+	2375:  "Docker",
+	2376:  "Docker-TLS",
+	2379:  "etcd",
+	3128:  "Squid",
+	3268:  "LDAP-GC",
+	3306:  "MySQL",
+	3389:  "RDP",
+	3690:  "SVN",
+	4369:  "EPMD",
+	5060:  "SIP",
+	5222:  "XMPP",
+	5432:  "PostgreSQL",
+	5601:  "Kibana",
+	5671:  "AMQPS",
+	5672:  "AMQP",
+	5900:  "VNC",
+	5984:  "CouchDB",
+	5985:  "WinRM",
+	5986:  "WinRM-TLS",
+	6379:  "Redis",
+	6443:  "Kubernetes",
+	6667:  "IRC",
+	7001:  "WebLogic",
+	8000:  "HTTP-Alt",
+	8080:  "HTTP-Alt",
+	8081:  "HTTP-Alt",
+	8086:  "InfluxDB",
+	8443:  "HTTPS-Alt",
+	8500:  "Consul",
+	8888:  "HTTP-Alt",
+	9042:  "Cassandra",
+	9090:  "Prometheus",
+	9092:  "Kafka",
+	9100:  "JetDirect",
+	9200:  "Elasticsearch",
+	9300:  "ES-Transport",
+	9418:  "Git",
+	10250: "Kubelet",
+	11211: "Memcached",
+	15672: "RabbitMQ-UI",
+	27017: "MongoDB",
 }
 
-type HostResult struct {
-	IP      string
-	Alive   bool
-	Ports   []PortResult
-	Latency time.Duration
+// NetworkDiscovery finds live hosts on network and scans them using the
+// default configuration
+func NetworkDiscovery(network string, ports []int) []models.HostResult {
+	return New(DefaultConfig()).NetworkDiscovery(network, ports)
 }
 
-// Common services for port identification
-var commonServices = map[int]string{
-	21:   "FTP",
-	22:   "SSH",
-	23:   "Telnet",
-	25:   "SMTP",
-	53:   "DNS",
-	80:   "HTTP",
-	110:  "POP3",
-	135:  "RPC",
-	139:  "NetBIOS",
-	143:  "IMAP",
-	443:  "HTTPS",
-	445:  "SMB",
-	993:  "IMAPS",
-	995:  "POP3S",
-	1433: "MSSQL",
-	3306: "MySQL",
-	3389: "RDP",
-	5432: "PostgreSQL",
-	5900: "VNC",
-	6379: "Redis",
-	8080: "HTTP-Alt",
-	9200: "Elasticsearch",
+// NetworkDiscovery finds live hosts on network, scans ports on each, prints
+// them and returns them sorted by IP. In detailed mode every enumerated
+// address is returned with its Status, not just the live ones.
+func (s *Scanner) NetworkDiscovery(network string, ports []int) []models.HostResult {
+	return s.NetworkDiscoveryContext(context.Background(), network, ports)
 }
 
-func generateIPs(network string) []string {
-	var ips []string
-
-	// Simple implementation for /24 networks
-	if strings.HasSuffix(network, "/24") {
-		base := strings.TrimSuffix(network, "/24")
-		baseIP := strings.Split(base, ".")
-		if len(baseIP) == 4 {
-			for i := 1; i < 255; i++ {
-				ip := fmt.Sprintf("%s.%s.%s.%d", baseIP[0], baseIP[1], baseIP[2], i)
-				ips = append(ips, ip)
-			}
+// NetworkDiscoveryContext is NetworkDiscovery, stopping early when ctx
+// ends: no new hosts or ports are started, probes in flight are abandoned,
+// and the hosts found so far are printed and returned. Every goroutine has
+// exited by the time it returns.
+func (s *Scanner) NetworkDiscoveryContext(ctx context.Context, network string, ports []int) []models.HostResult {
+	fmt.Fprintf(s.out, "\n🔍 Network discovery on %s\n", network)
+
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return nil
+	}
+
+	start := time.Now()
+	stream, scanned := s.discoverStream(ctx, scanOrder(s, ips), ports)
+	var allHosts []models.HostResult
+	for host := range stream {
+		allHosts = append(allHosts, host)
+	}
+	elapsed := time.Since(start)
+
+	liveHosts := 0
+	for _, host := range allHosts {
+		if host.Alive {
+			liveHosts++
 		}
 	}
 
-	return ips
+	// Sort results by IP
+	sort.Slice(allHosts, func(i, j int) bool {
+		return utils.CompareIPs(allHosts[i].IP, allHosts[j].IP)
+	})
+
+	if ctx.Err() != nil {
+		fmt.Fprintf(s.out, "\n⚠️  Discovery interrupted after %v, results are partial\n", elapsed)
+	} else {
+		fmt.Fprintf(s.out, "\n✅ Discovery completed in %v\n", elapsed)
+	}
+	fmt.Fprintf(s.out, "📊 Found %d live hosts out of %d scanned:\n\n", liveHosts, *scanned)
+
+	s.PrintHosts(allHosts)
+
+	return allHosts
 }
 
-func NetworkDiscovery(network string, ports []int) {
-	fmt.Printf("\n🔍 Network discovery on %s\n", network)
+// NetworkDiscoveryStream finds live hosts on network and scans them using
+// the default configuration, sending each on the returned channel when done
+func NetworkDiscoveryStream(ctx context.Context, network string, ports []int) <-chan models.HostResult {
+	return New(DefaultConfig()).NetworkDiscoveryStream(ctx, network, ports)
+}
 
-	ips := generateIPs(network)
+// NetworkDiscoveryStream finds live hosts on network and scans them like
+// NetworkDiscoveryContext, but sends each host on the returned channel as
+// soon as it's done instead of returning them all at the end, for progress
+// displays and consumers that stop early. Hosts arrive in the order they
+// finish, not sorted, and aren't printed. The channel is closed when the
+// discovery is over or ctx ends; a consumer that stops reading early must
+// cancel ctx to let the scan wind down. An invalid network is reported on
+// the scanner's output and yields no hosts.
+func (s *Scanner) NetworkDiscoveryStream(ctx context.Context, network string, ports []int) <-chan models.HostResult {
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		hosts := make(chan models.HostResult)
+		close(hosts)
+		return hosts
+	}
+	hosts, _ := s.discoverStream(ctx, scanOrder(s, ips), ports)
+	return hosts
+}
 
-	// Increased concurrency limits for better performance
-	const maxHostConcurrency = 100 // More hosts scanned simultaneously
-	const maxPortConcurrency = 50  // More ports per host
-	const batchSize = 50           // Process hosts in batches for better memory management
+// discoverStream runs discoverBatches over ips in the background, sending
+// each result on the returned channel. scanned holds how many hosts were
+// checked once the channel is closed.
+func (s *Scanner) discoverStream(ctx context.Context, ips []string, ports []int) (<-chan models.HostResult, *int) {
+	hosts := make(chan models.HostResult, orDefault(s.cfg.DiscoveryBatchSize, DefaultDiscoveryBatchSize))
+	scanned := new(int)
+	go func() {
+		defer close(hosts)
+		*scanned = s.discoverBatches(ctx, ips, ports, func(host models.HostResult) {
+			send(ctx, hosts, host)
+		})
+	}()
+	return hosts, scanned
+}
 
-	var allHosts []HostResult
-	var resultsMutex sync.Mutex
+// discoverBatches runs discoverHost over ips in batches, printing progress
+// and handing each result to fn as soon as its host is done. fn is never
+// called concurrently. Once ctx ends no more hosts are started; it returns
+// how many were checked.
+func (s *Scanner) discoverBatches(ctx context.Context, ips []string, ports []int, fn func(models.HostResult)) (scanned int) {
+	maxHostConcurrency, _ := s.discoveryConcurrency()
 
-	start := time.Now()
+	// Process hosts in batches for better memory management
+	batchSize := orDefault(s.cfg.DiscoveryBatchSize, DefaultDiscoveryBatchSize)
 
 	// Process IPs in batches to manage memory and provide progress feedback
-	for i := 0; i < len(ips); i += batchSize {
+	for i := 0; i < len(ips) && ctx.Err() == nil; i += batchSize {
 		end := i + batchSize
 		if end > len(ips) {
 			end = len(ips)
@@ -95,56 +217,31 @@ func NetworkDiscovery(network string, ports []int) {
 		batchStart := time.Now()
 
 		var wg sync.WaitGroup
-		results := make(chan HostResult, len(batch))
+		results := make(chan models.HostResult, len(batch))
 		sem := make(chan struct{}, maxHostConcurrency)
 
+		var checked atomic.Int64
+	dispatch:
 		for _, ip := range batch {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break dispatch
+			}
+
 			wg.Add(1)
 			go func(ip string) {
 				defer wg.Done()
-				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				// Use the faster ping method first
-				if !pingHostFast(ip) {
+				host, ok := s.discoverHost(ctx, ip, ports)
+				if ctx.Err() != nil && !host.Alive {
+					// The ping was abandoned, so the host is unknown
 					return
 				}
-
-				// Scan ports concurrently for this host
-				var portWg sync.WaitGroup
-				portResults := make(chan PortResult, len(ports))
-				portSem := make(chan struct{}, maxPortConcurrency)
-
-				for _, port := range ports {
-					portWg.Add(1)
-					go func(port int) {
-						defer portWg.Done()
-						portSem <- struct{}{}
-						defer func() { <-portSem }()
-
-						result := scanPortFast(ip, port)
-						if result.Open {
-							portResults <- result
-						}
-					}(port)
-				}
-
-				go func() {
-					portWg.Wait()
-					close(portResults)
-				}()
-
-				var openPorts []PortResult
-				for result := range portResults {
-					openPorts = append(openPorts, result)
-				}
-
-				if len(openPorts) > 0 || len(ports) == 0 {
-					results <- HostResult{
-						IP:    ip,
-						Alive: true,
-						Ports: openPorts,
-					}
+				checked.Add(1)
+				if ok {
+					results <- host
 				}
 			}(ip)
 		}
@@ -154,163 +251,314 @@ func NetworkDiscovery(network string, ports []int) {
 			close(results)
 		}()
 
-		// Collect batch results
-		var batchHosts []HostResult
+		// Hand results over as they come in
+		batchAlive := 0
 		for result := range results {
-			batchHosts = append(batchHosts, result)
+			fn(result)
+			if result.Alive {
+				batchAlive++
+			}
 		}
 
-		resultsMutex.Lock()
-		allHosts = append(allHosts, batchHosts...)
-		resultsMutex.Unlock()
+		scanned += int(checked.Load())
 
 		batchElapsed := time.Since(batchStart)
-		fmt.Printf("📈 Batch %d/%d: %d hosts found in %v\n",
+		fmt.Fprintf(s.out, "📈 Batch %d/%d: %d hosts found in %v\n",
 			(i/batchSize)+1, (len(ips)+batchSize-1)/batchSize,
-			len(batchHosts), batchElapsed)
+			batchAlive, batchElapsed)
 	}
+	return scanned
+}
 
-	elapsed := time.Since(start)
+// DiscoverMap runs NetworkDiscovery with the default configuration and
+// returns the hosts keyed by IP
+func DiscoverMap(network string, ports []int) map[string]models.HostResult {
+	return New(DefaultConfig()).DiscoverMap(network, ports)
+}
 
-	// Sort results by IP
-	sort.Slice(allHosts, func(i, j int) bool {
-		return compareIPs(allHosts[i].IP, allHosts[j].IP)
-	})
+// DiscoverMap runs NetworkDiscovery and returns the hosts keyed by IP
+func (s *Scanner) DiscoverMap(network string, ports []int) map[string]models.HostResult {
+	return HostMap(s.NetworkDiscovery(network, ports))
+}
 
-	fmt.Printf("\n✅ Discovery completed in %v\n", elapsed)
-	fmt.Printf("📊 Found %d live hosts out of %d scanned:\n\n", len(allHosts), len(ips))
+// HostMap indexes hosts by IP. If an IP appears more than once the last
+// result wins.
+func HostMap(hosts []models.HostResult) map[string]models.HostResult {
+	m := make(map[string]models.HostResult, len(hosts))
+	for _, host := range hosts {
+		m[host.IP] = host
+	}
+	return m
+}
 
-	for _, host := range allHosts {
-		fmt.Printf("🖥️  %s\n", host.IP)
-		if len(host.Ports) > 0 {
-			// Sort ports for consistent output
-			sort.Slice(host.Ports, func(i, j int) bool {
-				return host.Ports[i].Port < host.Ports[j].Port
-			})
+// scanHostPorts scans ports on a single host concurrently, returning the open
+// ones and any that couldn't be probed (and the others with IncludeClosed). answered reports whether any port
+// was open or actively refused, either of which proves the host is up. Ports
+// not yet started when ctx ends are skipped. honeypot reports whether the
+// host tripped HoneypotThreshold, in which case the scan may have stopped
+// early under HoneypotStop.
+func (s *Scanner) scanHostPorts(ctx context.Context, ip string, ports []int) (results []models.PortResult, answered, honeypot bool) {
+	_, maxPortConcurrency := s.discoveryConcurrency()
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var portWg sync.WaitGroup
+	portResults := make(chan models.PortResult, len(ports))
+	portSem := make(chan struct{}, maxPortConcurrency)
+
+dispatch:
+	for _, port := range scanOrder(s, ports) {
+		select {
+		case portSem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
 
-			for _, port := range host.Ports {
-				service := port.Service
-				if service == "" {
-					service = "Unknown"
-				}
-				fmt.Printf("   🟢 %-5d %-12s", port.Port, service)
-				if port.Banner != "" {
-					fmt.Printf(" - %s", port.Banner)
-				}
-				fmt.Println()
+		portWg.Add(1)
+		go func(port int) {
+			defer portWg.Done()
+			defer func() { <-portSem }()
+
+			result := s.scanPortFast(ctx, ip, port)
+			if !result.Open && ctx.Err() != nil {
+				// The dial was abandoned, so the state says nothing
+				return
 			}
-		} else {
-			fmt.Printf("   📝 Host alive but no open ports found in scanned range\n")
+			portResults <- result
+		}(port)
+	}
+
+	go func() {
+		portWg.Wait()
+		close(portResults)
+	}()
+
+	scanned, open := 0, 0
+	for result := range portResults {
+		scanned++
+		if result.Open || result.State == models.StateClosed {
+			answered = true
+		}
+		if result.Open {
+			open++
+		}
+		if result.Open || result.State == models.StateError || s.cfg.IncludeClosed {
+			results = append(results, result)
+		}
+		if s.cfg.HoneypotStop && s.suspectHoneypot(open, scanned) {
+			stop()
+		}
+	}
+	return results, answered, s.suspectHoneypot(open, scanned)
+}
+
+// discoverHost pings ip and scans ports on it if it's alive. The returned
+// bool reports whether the host belongs in the discovery results: normally
+// only live hosts with open ports do, in detailed mode every host does.
+func (s *Scanner) discoverHost(ctx context.Context, ip string, ports []int) (host models.HostResult, ok bool) {
+	host = models.HostResult{IP: ip}
+	if s.excluded(ip) {
+		host.Status = models.StatusExcluded
+		return host, s.cfg.Detailed
+	}
+
+	start := time.Now()
+	defer func() { host.ScanDuration = time.Since(start) }()
+
+	if s.cfg.PingScanPorts {
+		// The scan itself is the liveness check
+		host.Ports, host.Alive, host.SuspectedHoneypot = s.scanHostPorts(ctx, ip, ports)
+		if !host.Alive {
+			host.Status = models.StatusNoResponse
+			return host, s.cfg.Detailed
+		}
+	} else {
+		// Use the faster ping method first
+		var err error
+		host.Alive, host.Latency, err = s.pingHostFast(ctx, ip)
+		if !host.Alive {
+			host.PingError = err.Error()
+			host.Status = models.StatusNoResponse
+			return host, s.cfg.Detailed
+		}
+		host.Ports, _, host.SuspectedHoneypot = s.scanHostPorts(ctx, ip, ports)
+	}
+	s.capPorts(&host)
+	s.resolvePTR(ctx, &host)
+	s.resolveNetBIOS(&host)
+	s.detectOS(ctx, &host)
+
+	if host.OpenCount() > 0 {
+		host.Status = models.StatusOpenPorts
+		return host, true
+	}
+
+	host.Status = models.StatusNoPorts
+	return host, len(ports) == 0 || s.cfg.Detailed
+}
+
+// Why a ping found a host down, see PingHostFast. ErrPingRefused means the
+// host itself answered, so it's up but has none of the probed ports open;
+// ErrPingTimeout means nothing answered at all, as with a host that's off
+// or behind a firewall dropping the probes.
+var (
+	ErrPingRefused     = errors.New("every probe was refused")
+	ErrPingUnreachable = errors.New("host unreachable")
+	ErrPingTimeout     = errors.New("no reply before the timeout")
+)
+
+// PingHost reports whether ip is up using the default configuration
+func PingHost(ip string) bool {
+	alive, _, _ := New(DefaultConfig()).PingHostFast(ip)
+	return alive
+}
+
+// PingHostFast checks whether ip is up using the default configuration
+func PingHostFast(ip string) (bool, time.Duration, error) {
+	return New(DefaultConfig()).PingHostFast(ip)
+}
+
+// PingHostFast checks whether ip is up using the configured PingMethod and
+// returns the latency of the probe that proved it. For a host that isn't,
+// the error says why: ErrPingRefused, ErrPingUnreachable, ErrPingTimeout,
+// or the error that kept the probes from being sent.
+func (s *Scanner) PingHostFast(ip string) (bool, time.Duration, error) {
+	return s.pingHostFast(context.Background(), ip)
+}
+
+// pingHostFast is PingHostFast, giving up when parent ends
+func (s *Scanner) pingHostFast(parent context.Context, ip string) (bool, time.Duration, error) {
+	if s.cfg.PingMethod == PingICMP && s.cfg.Proxy == nil && s.cfg.Dialer == nil {
+		alive, rtt, err := s.pingICMP(parent, ip)
+		if !errors.Is(err, ErrICMPUnavailable) {
+			if !alive && err == nil {
+				err = ErrPingTimeout
+			}
+			return alive, rtt, err
 		}
-		fmt.Println()
 	}
+	return s.pingTCP(parent, ip)
 }
 
-// another helper
+// pingPorts are the common ports the TCP ping tries all at once
+var pingPorts = []int{80, 443, 22, 21, 23, 25, 53, 135, 139, 445}
+
 // Fast ping using TCP connect instead of ICMP
-func pingHostFast(ip string) bool {
-	// Try multiple common ports quickly
-	ports := []int{80, 443, 22, 21, 23, 25, 53, 135, 139, 445}
+func (s *Scanner) pingTCP(parent context.Context, ip string) (bool, time.Duration, error) {
+	ports := pingPorts
+
+	// Don't let a pause eat into the probe budget
+	s.waitWhilePaused(parent)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(parent, 2*s.pingTimeout())
 	defer cancel()
 
-	// Use a channel to return as soon as any port responds
+	// Use a channel to return as soon as any port responds. Failures are
+	// collected to tell why the host isn't up once every probe has one.
 	success := make(chan bool, len(ports))
+	failures := make(chan error, len(ports))
 
 	for _, port := range ports {
 		go func(p int) {
-			address := fmt.Sprintf("%s:%d", ip, p)
-			conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond)
-			if err == nil {
-				conn.Close()
-				select {
-				case success <- true:
-				default:
-				}
+			address := hostPort(ip, p)
+			conn, err := s.dial(ctx, address, s.pingTimeout())
+			if err != nil {
+				failures <- err
+				return
+			}
+			s.closeConn(conn)
+			select {
+			case success <- true:
+			default:
 			}
 		}(port)
 	}
 
-	select {
-	case <-success:
-		return true
-	case <-ctx.Done():
-		return false
+	var errs []error
+	for {
+		select {
+		case <-success:
+			return true, time.Since(start), nil
+		case err := <-failures:
+			if errs = append(errs, err); len(errs) == len(ports) {
+				return false, 0, pingFailure(errs)
+			}
+		case <-ctx.Done():
+			return false, 0, pingFailure(errs)
+		}
 	}
 }
 
-// Optimized port scanning function with shorter timeouts
-func scanPortFast(host string, port int) PortResult {
-	timeout := 1 * time.Second // Reduced from 3 seconds
-	target := fmt.Sprintf("%s:%d", host, port)
-
-	conn, err := net.DialTimeout("tcp", target, timeout)
-	if err != nil {
-		return PortResult{Port: port, Open: false}
+// pingFailure explains a TCP ping that failed with errs, one per probe that
+// finished. Any other error, such as a failed lookup, is returned as is.
+func pingFailure(errs []error) error {
+	refused := 0
+	for _, err := range errs {
+		switch {
+		case isUnreachable(err):
+			return ErrPingUnreachable
+		case isRefused(err):
+			refused++
+		case isTimeout(err), errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		default:
+			return err
+		}
 	}
-	defer conn.Close()
-
-	service := commonServices[port]
-	banner := grabBannerFast(conn, port)
-
-	return PortResult{
-		Port:    port,
-		Open:    true,
-		Service: service,
-		Banner:  banner,
+	if refused == len(pingPorts) {
+		return ErrPingRefused
 	}
+	return ErrPingTimeout
 }
 
-// Faster banner grabbing with shorter timeout
-func grabBannerFast(conn net.Conn, port int) string {
-	conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond)) // Reduced timeout
-
-	// Send appropriate probe based on port
-	switch port {
-	case 22:
-		// SSH typically sends banner immediately
-	case 80, 8080:
-		conn.Write([]byte("GET / HTTP/1.1\r\nHost: \r\nConnection: close\r\n\r\n"))
-	case 25:
-		// SMTP sends banner immediately
-	case 21:
-		// FTP sends banner immediately
-	case 443:
-		// HTTPS - don't try to grab banner as it requires TLS handshake
-		return ""
-	}
+// Optimized port scanning function with shorter timeouts
+func (s *Scanner) scanPortFast(ctx context.Context, host string, port int) models.PortResult {
+	target := hostPort(host, port)
 
-	buffer := make([]byte, 512) // Smaller buffer
-	n, err := conn.Read(buffer)
+	conn, latency, err := s.dialRetrying(ctx, target, s.dialTimeout())
 	if err != nil {
-		return ""
+		return dialFailure(port, err)
 	}
-
-	banner := string(buffer[:n])
-	banner = strings.ReplaceAll(banner, "\r\n", " ")
-	banner = strings.ReplaceAll(banner, "\n", " ")
-	banner = strings.TrimSpace(banner)
-
-	if len(banner) > 40 { // Shorter banner limit
-		banner = banner[:40] + "..."
+	defer s.closeConn(conn)
+	conn = s.throttle(conn)
+
+	result := models.PortResult{
+		Port:     port,
+		Protocol: models.ProtoTCP,
+		Open:     true,
+		State:    models.StateOpen,
+		Service:  s.serviceName(port),
+		Latency:  latency,
 	}
 
-	return banner
+	opts := s.bannerOptions(banner.FastOptions)
+	if banner.TLSPorts[port] {
+		s.grabTLS(conn, host, port, opts, &result)
+		s.fingerprintHTTP(ctx, target, host, opts, &result)
+		return result
+	}
+	s.setBanner(&result, banner.Grab(conn, port, opts))
+	s.fingerprintHTTP(ctx, target, host, opts, &result)
+	return result
 }
 
 // Alternative implementation using worker pools for even better performance
-func networkDiscoveryWorkerPool(network string, ports []int) {
-	fmt.Printf("\n🔍 Network discovery on %s (Worker Pool)\n", network)
+func (s *Scanner) networkDiscoveryWorkerPool(network string, ports []int) {
+	fmt.Fprintf(s.out, "\n🔍 Network discovery on %s (Worker Pool)\n", network)
 
-	ips := generateIPs(network)
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return
+	}
 
 	const numWorkers = 50
 	const bufferSize = 100
 
 	// Create channels
 	jobs := make(chan string, bufferSize)
-	results := make(chan HostResult, bufferSize)
+	results := make(chan models.HostResult, bufferSize)
 
 	var wg sync.WaitGroup
 
@@ -322,40 +570,10 @@ func networkDiscoveryWorkerPool(network string, ports []int) {
 		go func() {
 			defer wg.Done()
 			for ip := range jobs {
-				if !pingHostFast(ip) {
-					continue
-				}
-
-				var portResults []PortResult
-				var portWg sync.WaitGroup
-				portChan := make(chan PortResult, len(ports))
-
-				for _, port := range ports {
-					portWg.Add(1)
-					go func(port int) {
-						defer portWg.Done()
-						result := scanPortFast(ip, port)
-						if result.Open {
-							portChan <- result
-						}
-					}(port)
-				}
-
-				go func() {
-					portWg.Wait()
-					close(portChan)
-				}()
-
-				for result := range portChan {
-					portResults = append(portResults, result)
-				}
-
-				if len(portResults) > 0 {
-					results <- HostResult{
-						IP:    ip,
-						Alive: true,
-						Ports: portResults,
-					}
+				// Same per-host handling as the batched variant, including
+				// its cap on concurrent port dials
+				if host, ok := s.discoverHost(context.Background(), ip, ports); ok {
+					results <- host
 				}
 			}
 		}()
@@ -375,58 +593,186 @@ func networkDiscoveryWorkerPool(network string, ports []int) {
 		close(results)
 	}()
 
-	var hosts []HostResult
+	var hosts []models.HostResult
+	liveHosts := 0
 	for result := range results {
 		hosts = append(hosts, result)
+		if result.Alive {
+			liveHosts++
+		}
 	}
 
 	elapsed := time.Since(start)
 
 	sort.Slice(hosts, func(i, j int) bool {
-		return compareIPs(hosts[i].IP, hosts[j].IP)
+		return utils.CompareIPs(hosts[i].IP, hosts[j].IP)
 	})
 
-	fmt.Printf("\n✅ Discovery completed in %v\n", elapsed)
-	fmt.Printf("📊 Found %d live hosts out of %d scanned:\n\n", len(hosts), len(ips))
+	fmt.Fprintf(s.out, "\n✅ Discovery completed in %v\n", elapsed)
+	fmt.Fprintf(s.out, "📊 Found %d live hosts out of %d scanned:\n\n", liveHosts, len(ips))
+
+	s.PrintHosts(hosts)
+}
+
+// stateMarker returns the marker used to display a port's state
+func stateMarker(port models.PortResult) string {
+	switch {
+	case port.Open:
+		return "🟢"
+	case port.State == models.StateClosed:
+		return "🔴"
+	default:
+		return "🟡"
+	}
+}
 
+// dialState classifies a failed connection attempt. A reset means the host
+// answered but nothing listens there; no answer at all means the probe was
+// dropped on the way, most likely by a firewall. Running out of file
+// descriptors means the probe was never sent.
+func dialState(err error) string {
+	switch {
+	case isRefused(err):
+		return models.StateClosed
+	case isFDExhausted(err):
+		return models.StateError
+	}
+	return models.StateFiltered
+}
+
+// dialFailure builds the result for a port whose dial failed
+func dialFailure(port int, err error) models.PortResult {
+	result := models.PortResult{Port: port, Protocol: models.ProtoTCP, State: dialState(err)}
+	if result.State == models.StateError {
+		result.Error = ErrTooManyOpenFiles.Error()
+	}
+	return result
+}
+
+// Shortest run of contiguous open ports that PrintHosts collapses into a range
+const minCollapsedRun = 3
+
+// runEnd returns the index of the last port in the run of contiguous open
+// ports starting at ports[i]. Ports with a banner end the run since their
+// banner is worth its own line. ports must be sorted.
+func runEnd(ports []models.PortResult, i int) int {
+	if !ports[i].Open || ports[i].Banner != "" {
+		return i
+	}
+
+	end := i
+	for end+1 < len(ports) {
+		next := ports[end+1]
+		if !next.Open || next.Banner != "" || next.Port != ports[end].Port+1 {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// hostLabel returns host's name, qualified by its workgroup if known
+func hostLabel(host models.HostResult) string {
+	if host.Workgroup != "" {
+		return host.Workgroup + `\` + host.Hostname
+	}
+	return host.Hostname
+}
+
+// PrintHosts prints hosts using the default configuration
+func PrintHosts(hosts []models.HostResult) {
+	New(DefaultConfig()).PrintHosts(hosts)
+}
+
+// PrintHosts prints each host with its open ports, sorted by port number.
+// Hosts that didn't respond (only present in detailed mode) are marked as such.
+func (s *Scanner) PrintHosts(hosts []models.HostResult) {
 	for _, host := range hosts {
-		fmt.Printf("🖥️  %s\n", host.IP)
+		if !host.Alive {
+			fmt.Fprintf(s.out, "⚫ %s\n", host.IP)
+			switch host.Status {
+			case models.StatusExcluded:
+				fmt.Fprintf(s.out, "   📝 Excluded from scan\n")
+			default:
+				fmt.Fprintf(s.out, "   📝 No response to liveness probe\n")
+			}
+			fmt.Fprintln(s.out)
+			continue
+		}
+
+		fmt.Fprintf(s.out, "🖥️  %s", host.IP)
+		if host.Hostname != "" {
+			fmt.Fprintf(s.out, " (%s)", hostLabel(host))
+		}
+		if host.ScanDuration > 0 {
+			fmt.Fprintf(s.out, " (scanned in %v)", host.ScanDuration.Round(time.Millisecond))
+		}
+		fmt.Fprintln(s.out)
+		if host.OS != "" {
+			fmt.Fprintf(s.out, "   💻 OS: %s\n", host.OS)
+		}
+		if len(host.Addresses) > 1 {
+			fmt.Fprintf(s.out, "   📍 Addresses: %s\n", strings.Join(host.Addresses, ", "))
+		}
 		if len(host.Ports) > 0 {
+			// Sort ports for consistent output
 			sort.Slice(host.Ports, func(i, j int) bool {
 				return host.Ports[i].Port < host.Ports[j].Port
 			})
 
-			for _, port := range host.Ports {
+			for i := 0; i < len(host.Ports); i++ {
+				port := host.Ports[i]
+
+				// Collapse runs of plain open ports (8000-8010) into one line
+				if end := runEnd(host.Ports, i); end-i+1 >= minCollapsedRun {
+					fmt.Fprintf(s.out, "   🟢 %d-%d open (%d ports)\n", port.Port, host.Ports[end].Port, end-i+1)
+					i = end
+					continue
+				}
+
 				service := port.Service
 				if service == "" {
 					service = "Unknown"
 				}
-				fmt.Printf("   🟢 %-5d %-12s", port.Port, service)
+				fmt.Fprintf(s.out, "   %s %-5d %-12s", stateMarker(port), port.Port, service)
+				if port.Version != "" {
+					fmt.Fprintf(s.out, " [%s]", port.Version)
+				}
+				if !port.Open {
+					fmt.Fprintf(s.out, " (%s)", port.State)
+				}
+				if port.TLSOnly {
+					fmt.Fprintf(s.out, " (TLS only)")
+				}
+				if port.Unresponsive {
+					fmt.Fprintf(s.out, " (no service response)")
+				}
+				if note := clientCertNote(port); note != "" {
+					fmt.Fprintf(s.out, " (%s)", note)
+				}
 				if port.Banner != "" {
-					fmt.Printf(" - %s", port.Banner)
+					fmt.Fprintf(s.out, " - %s", s.displayBanner(port.Banner))
+				}
+				fmt.Fprintln(s.out)
+				if note := headerNote(port); note != "" {
+					fmt.Fprintf(s.out, "      🛡️  %s\n", note)
+				}
+				if note := httpNote(port); note != "" {
+					fmt.Fprintf(s.out, "      🌐 %s\n", note)
 				}
-				fmt.Println()
+				if note := certNote(port, time.Now()); note != "" {
+					fmt.Fprintf(s.out, "      🔒 %s\n", note)
+				}
+			}
+			if host.PortsTruncated {
+				fmt.Fprintf(s.out, "   ⚠️  Showing first %d open ports - all/many ports open (likely honeypot or tarpit)\n", len(host.Ports))
+			}
+			if host.SuspectedHoneypot {
+				fmt.Fprintf(s.out, "   🍯 Suspected honeypot - nearly every scanned port is open\n")
 			}
 		} else {
-			fmt.Printf("   📝 Host alive but no open ports found in scanned range\n")
-		}
-		fmt.Println()
-	}
-}
-
-// Helper function to properly sort IPs numerically
-func compareIPs(ip1, ip2 string) bool {
-	// Convert IPs to comparable format
-	parts1 := strings.Split(ip1, ".")
-	parts2 := strings.Split(ip2, ".")
-
-	for i := 0; i < 4; i++ {
-		var n1, n2 int
-		fmt.Sscanf(parts1[i], "%d", &n1)
-		fmt.Sscanf(parts2[i], "%d", &n2)
-		if n1 != n2 {
-			return n1 < n2
+			fmt.Fprintf(s.out, "   📝 Host alive but no open ports found in scanned range\n")
 		}
+		fmt.Fprintln(s.out)
 	}
-	return false
 }
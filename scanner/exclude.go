@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"netscan/utils"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// ErrExcluded is returned for connections to addresses on the exclusion list
+var ErrExcluded = errors.New("address excluded from scanning")
+
+// LoadExclusions reads a list of addresses that must never be scanned, one
+// IP address or CIDR network per line. Blank lines and lines starting with #
+// are skipped. Any other line that isn't an address or network is an error,
+// so a typo can't quietly leave a protected host unlisted. Set the result as
+// ScanConfig.Exclude.
+func LoadExclusions(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var exclusions []string
+	lines := bufio.NewScanner(f)
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if utils.ParseExclusion(line) == nil {
+			return nil, fmt.Errorf("%s:%d: invalid address or network %q", path, n, line)
+		}
+		exclusions = append(exclusions, line)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	return exclusions, nil
+}
+
+// Excluded reports whether host is on the exclusion list: an IP address in
+// one of its networks, or a name that resolves, with the configured
+// resolver, to one
+func (s *Scanner) Excluded(host string) bool {
+	err := s.checkTarget(context.Background(), hostPort(host, 0))
+	return errors.Is(err, ErrExcluded)
+}
+
+// excluded reports whether host is an address on the exclusion list.
+// Host names are never excluded here; control catches them once resolved.
+func (s *Scanner) excluded(host string) bool {
+	return utils.Excluded(host, s.cfg.Exclude)
+}
+
+// control runs on every socket just before it connects, after any hostname
+// has been resolved, so the exclusion list holds however a target was
+// written
+func (s *Scanner) control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if s.excluded(host) {
+		return fmt.Errorf("%s: %w", host, ErrExcluded)
+	}
+	if s.cfg.SourcePort > 0 {
+		return reuseAddr(network, address, c)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package scanner
+
+import "syscall"
+
+// fdLimit returns the process's open file limit (ulimit -n), or 0 if it
+// can't be read. Go raises the soft limit to the hard limit at startup, so
+// this is usually the hard limit.
+func fdLimit() int {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0
+	}
+	if rl.Cur > 1<<30 {
+		return 1 << 30
+	}
+	return int(rl.Cur)
+}
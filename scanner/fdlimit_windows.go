@@ -0,0 +1,9 @@
+//go:build windows
+
+package scanner
+
+// fdLimit returns 0 since Windows has no per-process socket limit to fit
+// under
+func fdLimit() int {
+	return 0
+}
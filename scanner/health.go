@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"netscan/models"
+	"slices"
+	"sync"
+)
+
+// HealthCheck checks a host's expected ports using the default configuration
+func HealthCheck(host string, expectedPorts []int) models.HealthStatus {
+	return New(DefaultConfig()).HealthCheck(host, expectedPorts)
+}
+
+// HealthCheck connects to each of expectedPorts on host, without grabbing
+// banners, and returns a verdict: up if every one is open, down if none is
+// and degraded in between, listing the open and missing ports in ascending
+// order, each once however many times it's expected. A host with no
+// expected ports is up.
+func (s *Scanner) HealthCheck(host string, expectedPorts []int) models.HealthStatus {
+	status := models.HealthStatus{Host: host}
+
+	ports := slices.Clone(expectedPorts)
+	slices.Sort(ports)
+	ports = slices.Compact(ports)
+
+	// open[i] is set when ports[i] accepts a connection
+	open := make([]bool, len(ports))
+	_, maxPortConcurrency := s.discoveryConcurrency()
+	sem := make(chan struct{}, maxPortConcurrency)
+	var wg sync.WaitGroup
+	for i, port := range ports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			conn, _, err := s.dialRetrying(context.Background(), hostPort(host, port), s.dialTimeout())
+			if err == nil {
+				s.closeConn(conn)
+				open[i] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, port := range ports {
+		if open[i] {
+			status.Open = append(status.Open, port)
+		} else {
+			status.Missing = append(status.Missing, port)
+		}
+	}
+
+	switch {
+	case len(status.Missing) == 0:
+		status.Verdict = models.HealthUp
+	case len(status.Open) == 0:
+		status.Verdict = models.HealthDown
+	default:
+		status.Verdict = models.HealthDegraded
+	}
+	return status
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"netscan/models"
+	"netscan/utils"
+	"time"
+)
+
+// ScanHost scans a single host using the default configuration
+func ScanHost(host string, ports []int) models.HostResult {
+	return New(DefaultConfig()).ScanHost(host, ports)
+}
+
+// ScanHost pings host, measures its latency, scans ports on it and returns
+// the assembled result. Unlike discovery, the ports are scanned even if the
+// liveness probe gets no answer, and any port that answers, open or
+// refused, marks the host alive.
+func (s *Scanner) ScanHost(host string, ports []int) models.HostResult {
+	return s.scanHost(context.Background(), host, ports)
+}
+
+// scanHost is ScanHost, skipping any ports not yet started when ctx ends
+func (s *Scanner) scanHost(ctx context.Context, host string, ports []int) (result models.HostResult) {
+	host = utils.BareHost(host)
+	result = models.HostResult{IP: host}
+	if s.excluded(host) {
+		result.Status = models.StatusExcluded
+		return result
+	}
+
+	pingStart := time.Now()
+	defer func() { result.ScanDuration = time.Since(pingStart) }()
+	alive := false
+	var pingErr error
+	if !s.cfg.PingScanPorts {
+		alive, result.Latency, pingErr = s.pingHostFast(ctx, host)
+	}
+
+	var answered bool
+	result.Ports, answered, result.SuspectedHoneypot = s.scanHostPorts(ctx, host, ports)
+	s.capPorts(&result)
+
+	result.Alive = alive || answered
+	s.resolvePTR(ctx, &result)
+	s.resolveNetBIOS(&result)
+	s.detectOS(ctx, &result)
+	switch {
+	case result.OpenCount() > 0:
+		result.Status = models.StatusOpenPorts
+	case result.Alive:
+		result.Status = models.StatusNoPorts
+	default:
+		result.Status = models.StatusNoResponse
+		if pingErr != nil {
+			result.PingError = pingErr.Error()
+		}
+	}
+
+	return result
+}
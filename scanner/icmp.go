@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"netscan/utils"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// PingMethod selects how discovery decides whether a host is up
+type PingMethod int
+
+const (
+	// PingTCP connects to a handful of common ports and counts the host up
+	// if any of them accepts. It needs no privileges but misses hosts
+	// firewalled on all of those ports.
+	PingTCP PingMethod = iota
+
+	// PingICMP sends an ICMP echo request and waits for the reply, with
+	// the latency taken from the round trip. It needs raw sockets (root or
+	// CAP_NET_RAW) or, on Linux, a ping_group_range that covers the
+	// process; without either, and through a Proxy, it falls back to
+	// PingTCP. Hosts that drop ICMP look down.
+	PingICMP
+)
+
+func (m PingMethod) String() string {
+	switch m {
+	case PingICMP:
+		return "icmp"
+	default:
+		return "tcp"
+	}
+}
+
+// ErrICMPUnavailable is returned when no ICMP socket could be opened,
+// usually for lack of privileges
+var ErrICMPUnavailable = errors.New("ICMP unavailable")
+
+// PingHostICMP pings ip with an ICMP echo using the default configuration
+func PingHostICMP(ip string) (bool, time.Duration, error) {
+	return New(DefaultConfig()).PingHostICMP(ip)
+}
+
+// PingHostICMP sends an ICMP echo request to ip and reports whether it
+// replied within twice PingTimeout, and the round-trip time if it did. It
+// tries an unprivileged datagram socket first and then a raw one, returning
+// ErrICMPUnavailable if neither can be opened. Addresses on the exclusion
+// list fail with ErrExcluded.
+func (s *Scanner) PingHostICMP(ip string) (bool, time.Duration, error) {
+	return s.pingICMP(context.Background(), ip)
+}
+
+// pingICMP is PingHostICMP, giving up when ctx ends
+func (s *Scanner) pingICMP(ctx context.Context, host string) (bool, time.Duration, error) {
+	alive, rtt, _, err := s.echoICMP(ctx, host)
+	return alive, rtt, err
+}
+
+// echoICMP is pingICMP, also returning the TTL (hop limit on IPv6) the
+// reply arrived with, or 0 where the socket doesn't report it
+func (s *Scanner) echoICMP(ctx context.Context, host string) (alive bool, rtt time.Duration, ttl int, err error) {
+	s.waitWhilePaused(ctx)
+
+	host = utils.BareHost(host)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addrs, err := s.resolver().LookupIP(ctx, "ip", host)
+		if err != nil {
+			return false, 0, 0, err
+		}
+		ip = addrs[0]
+	}
+	if s.excluded(ip.String()) {
+		return false, 0, 0, fmt.Errorf("%s: %w", ip, ErrExcluded)
+	}
+
+	v4 := ip.To4() != nil
+	conn, raw, err := listenICMP(v4)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	defer conn.Close()
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if !raw {
+		dst = &net.UDPAddr{IP: ip}
+	}
+
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	proto := 1 // ICMP
+	if !v4 {
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		proto = 58 // ICMPv6
+	}
+
+	// The kernel rewrites the ID on datagram sockets, so replies are
+	// matched on sequence number and payload instead
+	seq := int(time.Now().UnixNano() & 0xffff)
+	payload := []byte("netscan")
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: payload},
+	}
+	wire, err := msg.Marshal(nil)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if err := s.waitRate(ctx); err != nil {
+		return false, 0, 0, err
+	}
+
+	deadline := time.Now().Add(2 * s.pingTimeout())
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	// Not every platform and socket type can report the TTL, in which case
+	// replies come back without it
+	if v4 {
+		conn.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	} else {
+		conn.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(wire, dst); err != nil {
+		return false, 0, 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, hops, err := readICMP(conn, v4, buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return false, 0, 0, nil
+			}
+			return false, 0, 0, err
+		}
+		rtt = time.Since(start)
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || reply.Type != replyType || !samePeer(peer, ip) {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq && string(echo.Data) == string(payload) {
+			return true, rtt, hops, nil
+		}
+	}
+}
+
+// listenICMP opens an ICMP socket, preferring an unprivileged datagram one.
+// raw reports whether it fell back to a raw socket.
+func listenICMP(v4 bool) (conn *icmp.PacketConn, raw bool, err error) {
+	dgram, rawNet, addr := "udp4", "ip4:icmp", "0.0.0.0"
+	if !v4 {
+		dgram, rawNet, addr = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	if conn, err := icmp.ListenPacket(dgram, addr); err == nil {
+		return conn, false, nil
+	}
+	conn, err = icmp.ListenPacket(rawNet, addr)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrICMPUnavailable, err)
+	}
+	return conn, true, nil
+}
+
+// readICMP reads one packet from conn, with the TTL or hop limit it arrived
+// with if the socket reports it, 0 otherwise
+func readICMP(conn *icmp.PacketConn, v4 bool, buf []byte) (n int, peer net.Addr, ttl int, err error) {
+	if v4 {
+		var cm *ipv4.ControlMessage
+		n, cm, peer, err = conn.IPv4PacketConn().ReadFrom(buf)
+		if cm != nil {
+			ttl = cm.TTL
+		}
+		return n, peer, ttl, err
+	}
+	var cm *ipv6.ControlMessage
+	n, cm, peer, err = conn.IPv6PacketConn().ReadFrom(buf)
+	if cm != nil {
+		ttl = cm.HopLimit
+	}
+	return n, peer, ttl, err
+}
+
+// samePeer reports whether a reply's source address is ip
+func samePeer(peer net.Addr, ip net.IP) bool {
+	switch a := peer.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
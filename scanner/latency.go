@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"netscan/models"
+	"sort"
+	"time"
+)
+
+// ProbeLatency measures connect latency using the default configuration
+func ProbeLatency(host string, port int, count int, interval time.Duration) models.LatencyReport {
+	return New(DefaultConfig()).ProbeLatency(host, port, count, interval)
+}
+
+// ProbeLatency connects to host:port count times, interval apart, and
+// reports the distribution of TCP connect times along with how many attempts
+// failed. Each attempt gets DialTimeout. Percentiles are computed over the
+// successful attempts only.
+func (s *Scanner) ProbeLatency(host string, port int, count int, interval time.Duration) models.LatencyReport {
+	report := models.LatencyReport{Host: host, Port: port}
+	address := hostPort(host, port)
+
+	var samples []time.Duration
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		// Only the connect is timed, not any wait while paused or over
+		// MaxConnsPerSec
+		conn, elapsed, err := s.dialTimed(context.Background(), address, s.dialTimeout())
+		report.Attempts++
+		if err != nil {
+			report.Failures++
+			continue
+		}
+		s.closeConn(conn)
+		samples = append(samples, elapsed)
+	}
+
+	if len(samples) == 0 {
+		return report
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	report.Min = samples[0]
+	report.Max = samples[len(samples)-1]
+	report.Mean = total / time.Duration(len(samples))
+	report.P50 = percentile(samples, 50)
+	report.P95 = percentile(samples, 95)
+	report.P99 = percentile(samples, 99)
+	return report
+}
+
+// percentile returns the nearest-rank pth percentile of sorted samples
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
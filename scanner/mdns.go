@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mDNS group address and the DNS-SD name that lists every advertised
+// service type
+const (
+	mdnsAddress     = "224.0.0.251:5353"
+	mdnsServiceList = "_services._dns-sd._udp.local."
+)
+
+// MDNSServiceTypes are queried directly as well as through service
+// enumeration, since some devices only answer for their own types
+var MDNSServiceTypes = []string{
+	"_http._tcp",
+	"_airplay._tcp",
+	"_raop._tcp",
+	"_googlecast._tcp",
+	"_ipp._tcp",
+	"_printer._tcp",
+	"_hap._tcp",
+	"_smb._tcp",
+	"_ssh._tcp",
+	"_spotify-connect._tcp",
+}
+
+// DiscoverMDNS finds mDNS devices using the default configuration
+func DiscoverMDNS(window time.Duration) []models.HostResult {
+	return New(DefaultConfig()).DiscoverMDNS(window)
+}
+
+// DiscoverMDNS queries the local network's mDNS group for advertised
+// services and listens for answers for window. Each responding device is
+// returned as a live host with its hostname and the service types it
+// advertises (e.g. "_airplay._tcp"). This finds phones, speakers, printers
+// and other IoT devices that often have no open ports a sweep would probe.
+//
+// Queries are sent from an ephemeral port, which makes responders answer by
+// unicast (RFC 6762 section 6.7), so no multicast group needs joining.
+func (s *Scanner) DiscoverMDNS(window time.Duration) []models.HostResult {
+	fmt.Fprintf(s.out, "\n🔍 Listening for mDNS advertisements for %v...\n", window)
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return nil
+	}
+	defer conn.Close()
+
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return nil
+	}
+
+	queried := make(map[string]bool)
+	query := func(name string) {
+		if queried[name] {
+			return
+		}
+		queried[name] = true
+		if msg, err := mdnsQuery(name); err == nil {
+			conn.WriteToUDP(msg, group)
+		}
+	}
+
+	s.waitWhilePaused(context.Background())
+	query(mdnsServiceList)
+	for _, service := range MDNSServiceTypes {
+		query(service + ".local.")
+	}
+
+	hosts := make(map[string]*models.HostResult)
+	buffer := make([]byte, 9000)
+	conn.SetReadDeadline(time.Now().Add(window))
+	for {
+		n, from, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			break
+		}
+
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buffer[:n]); err != nil || !msg.Response {
+			continue
+		}
+
+		ip := from.IP.String()
+		host, ok := hosts[ip]
+		if !ok {
+			host = &models.HostResult{IP: ip, Alive: true, Status: models.StatusNoPorts}
+			hosts[ip] = host
+		}
+
+		for _, r := range append(msg.Answers, msg.Additionals...) {
+			name := r.Header.Name.String()
+			switch body := r.Body.(type) {
+			case *dnsmessage.PTRResource:
+				if name == mdnsServiceList {
+					// Enumeration answer: ask who provides this type
+					query(body.PTR.String())
+					continue
+				}
+				addService(host, strings.TrimSuffix(name, ".local."))
+			case *dnsmessage.AResource:
+				if host.Hostname == "" && net.IP(body.A[:]).Equal(from.IP) {
+					host.Hostname = strings.TrimSuffix(name, ".")
+				}
+			case *dnsmessage.SRVResource:
+				if host.Hostname == "" {
+					host.Hostname = strings.TrimSuffix(body.Target.String(), ".")
+				}
+			}
+		}
+	}
+
+	var results []models.HostResult
+	for _, host := range hosts {
+		results = append(results, *host)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return utils.CompareIPs(results[i].IP, results[j].IP)
+	})
+
+	fmt.Fprintf(s.out, "📊 Found %d mDNS devices:\n\n", len(results))
+	for _, host := range results {
+		fmt.Fprintf(s.out, "🖥️  %s", host.IP)
+		if host.Hostname != "" {
+			fmt.Fprintf(s.out, " (%s)", host.Hostname)
+		}
+		fmt.Fprintln(s.out)
+		for _, service := range host.Advertised {
+			fmt.Fprintf(s.out, "   📡 %s\n", service)
+		}
+		fmt.Fprintln(s.out)
+	}
+
+	return results
+}
+
+// mdnsQuery builds a PTR query for name
+func mdnsQuery(name string) ([]byte, error) {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  n,
+			Type:  dnsmessage.TypePTR,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	return msg.Pack()
+}
+
+// addService records an advertised service type on host once
+func addService(host *models.HostResult, service string) {
+	for _, s := range host.Advertised {
+		if s == service {
+			return
+		}
+	}
+	host.Advertised = append(host.Advertised, service)
+}
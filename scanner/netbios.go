@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"netscan/models"
+	"strings"
+	"time"
+)
+
+// NetBIOS name service constants (RFC 1002)
+const (
+	nbstatType     = 0x21   // node status request
+	nbGroupFlag    = 0x8000 // name flag marking a group (workgroup/domain) name
+	nbWorkstation  = 0x00   // name suffix for the workstation service
+	nbNameEntryLen = 18     // 15-byte name, suffix byte, 2 flag bytes
+)
+
+var errNetBIOSShort = errors.New("netbios: truncated response")
+
+// NetBIOSName looks up ip's NetBIOS name using the default configuration
+func NetBIOSName(ip string) (hostname, workgroup string, err error) {
+	return New(DefaultConfig()).NetBIOSName(ip)
+}
+
+// NetBIOSName sends a NetBIOS node status query to UDP port 137 on ip and
+// returns the machine name and the workgroup or domain it belongs to.
+// Windows hosts (and Samba servers) answer this even when they expose no
+// TCP ports.
+func (s *Scanner) NetBIOSName(ip string) (hostname, workgroup string, err error) {
+	const timeout = time.Second
+
+	conn, err := s.dialUDP(hostPort(ip, 137))
+	if err != nil {
+		return "", "", err
+	}
+	defer conn.Close()
+
+	id := uint16(rand.Uint32())
+	conn.SetDeadline(time.Now().Add(s.jitter(timeout)))
+	if _, err := conn.Write(nbstatQuery(id)); err != nil {
+		return "", "", err
+	}
+
+	buffer := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return "", "", err
+		}
+		if n < 2 || binary.BigEndian.Uint16(buffer) != id {
+			continue
+		}
+		return parseNodeStatus(buffer[:n])
+	}
+}
+
+// resolveNetBIOS fills in host's Hostname and Workgroup when NetBIOS lookups
+// are enabled. Failures are ignored since most hosts don't run NetBIOS.
+func (s *Scanner) resolveNetBIOS(host *models.HostResult) {
+	if !s.cfg.NetBIOS || !host.Alive {
+		return
+	}
+	name, workgroup, err := s.NetBIOSName(host.IP)
+	if err != nil {
+		return
+	}
+	if host.Hostname == "" {
+		host.Hostname = name
+	}
+	host.Workgroup = workgroup
+}
+
+// nbstatQuery builds a node status request for the wildcard name "*"
+func nbstatQuery(id uint16) []byte {
+	msg := make([]byte, 12, 50)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[4:], 1) // one question
+
+	// First-level encoding: "*" padded with NULs to 16 bytes, each byte
+	// split into two nibbles written as 'A'+nibble
+	name := [16]byte{'*'}
+	msg = append(msg, 32)
+	for _, b := range name {
+		msg = append(msg, 'A'+b>>4, 'A'+b&0x0f)
+	}
+	msg = append(msg, 0)
+
+	msg = binary.BigEndian.AppendUint16(msg, nbstatType)
+	msg = binary.BigEndian.AppendUint16(msg, 1) // class IN
+	return msg
+}
+
+// parseNodeStatus extracts the workstation name and group name from a node
+// status response
+func parseNodeStatus(msg []byte) (hostname, workgroup string, err error) {
+	if len(msg) < 12 || binary.BigEndian.Uint16(msg[6:]) == 0 {
+		return "", "", errors.New("netbios: no answer in response")
+	}
+
+	// Skip the answer's name, then type, class, TTL and data length
+	pos := 12
+	for pos < len(msg) && msg[pos] != 0 {
+		if msg[pos]&0xc0 == 0xc0 { // compression pointer
+			pos++
+			break
+		}
+		pos += int(msg[pos]) + 1
+	}
+	pos += 1 + 10
+	if pos >= len(msg) {
+		return "", "", errNetBIOSShort
+	}
+
+	count := int(msg[pos])
+	pos++
+	for i := 0; i < count; i++ {
+		if pos+nbNameEntryLen > len(msg) {
+			return "", "", errNetBIOSShort
+		}
+		entry := msg[pos : pos+nbNameEntryLen]
+		pos += nbNameEntryLen
+
+		name := strings.TrimRight(string(entry[:15]), " \x00")
+		flags := binary.BigEndian.Uint16(entry[16:])
+		if entry[15] != nbWorkstation {
+			continue
+		}
+		switch {
+		case flags&nbGroupFlag != 0 && workgroup == "":
+			workgroup = name
+		case flags&nbGroupFlag == 0 && hostname == "":
+			hostname = name
+		}
+	}
+
+	if hostname == "" {
+		return "", "", errors.New("netbios: no workstation name in response")
+	}
+	return hostname, workgroup, nil
+}
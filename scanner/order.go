@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand/v2"
+)
+
+// newShuffler returns the source Randomize shuffles with: seeded with seed,
+// or from crypto/rand if seed is zero
+func newShuffler(seed uint64) *rand.Rand {
+	seed2 := seed
+	if seed == 0 {
+		var b [16]byte
+		crand.Read(b[:])
+		seed, seed2 = binary.LittleEndian.Uint64(b[:8]), binary.LittleEndian.Uint64(b[8:])
+	}
+	return rand.New(rand.NewPCG(seed, seed2))
+}
+
+// scanOrder returns list in the order to scan it: as given, or shuffled
+// into a copy with Randomize, so a scan doesn't walk ports or addresses in
+// an obvious sequence
+func scanOrder[T any](s *Scanner, list []T) []T {
+	if !s.cfg.Randomize {
+		return list
+	}
+	shuffled := append([]T(nil), list...)
+
+	// rand.Rand isn't safe for concurrent use
+	s.shuffleMu.Lock()
+	defer s.shuffleMu.Unlock()
+	s.shuffler.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"context"
+	"netscan/models"
+	"strings"
+)
+
+// Operating system families GuessOS tells apart
+const (
+	OSLinux   = "Linux/Unix"
+	OSWindows = "Windows"
+	OSNetwork = "Network device"
+	OSUnknown = "Unknown"
+)
+
+// osHint is a substring of a banner, version or Server header that points
+// to an OS family, and how many votes it's worth. Strong hints name the OS
+// outright; weak ones are software that merely usually runs on it.
+type osHint struct {
+	text   string
+	family string
+	votes  int
+}
+
+var osHints = []osHint{
+	{"windows", OSWindows, 2},
+	{"microsoft", OSWindows, 2},
+	{"iis", OSWindows, 2},
+	{"win32", OSWindows, 2},
+	{"win64", OSWindows, 2},
+	{"ubuntu", OSLinux, 2},
+	{"debian", OSLinux, 2},
+	{"centos", OSLinux, 2},
+	{"red hat", OSLinux, 2},
+	{"fedora", OSLinux, 2},
+	{"linux", OSLinux, 2},
+	{"freebsd", OSLinux, 2},
+	{"unix", OSLinux, 2},
+	{"openssh", OSLinux, 1},
+	{"apache", OSLinux, 1},
+	{"nginx", OSLinux, 1},
+	{"cisco", OSNetwork, 2},
+	{"mikrotik", OSNetwork, 2},
+	{"routeros", OSNetwork, 2},
+	{"junos", OSNetwork, 2},
+	{"fortinet", OSNetwork, 2},
+	{"fortigate", OSNetwork, 2},
+}
+
+// osPorts are ports whose being open points to an OS family on its own:
+// RDP and MSRPC only really run on Windows
+var osPorts = map[int]string{
+	3389: OSWindows,
+	135:  OSWindows,
+}
+
+// GuessOS guesses host's OS family using the default configuration
+func GuessOS(host string, ports []models.PortResult) string {
+	return New(DefaultConfig()).GuessOS(host, ports)
+}
+
+// GuessOS makes a coarse guess at host's OS family, one of OSLinux,
+// OSWindows, OSNetwork or OSUnknown, from cheap signals: the TTL of an ICMP
+// echo reply, since Linux and Unix start at 64, Windows at 128 and network
+// gear at 255, and hints in the banners of ports, an earlier scan of host.
+// A name in a banner outweighs the TTL, which a single router hop can't
+// push over a boundary but a tunnel or NAT can. No echo is sent through a
+// Proxy or Dialer, or without the privileges ICMP needs; the guess then
+// rests on the banners alone.
+func (s *Scanner) GuessOS(host string, ports []models.PortResult) string {
+	return s.guessOS(context.Background(), host, ports)
+}
+
+// guessOS is GuessOS, giving up on the echo when ctx ends
+func (s *Scanner) guessOS(ctx context.Context, host string, ports []models.PortResult) string {
+	votes := make(map[string]int)
+	if s.cfg.Proxy == nil && s.cfg.Dialer == nil {
+		if alive, _, ttl, err := s.echoICMP(ctx, host); alive && err == nil {
+			if family := ttlFamily(ttl); family != "" {
+				votes[family]++
+			}
+		}
+	}
+
+	for _, port := range ports {
+		if !port.Open {
+			continue
+		}
+		if family, ok := osPorts[port.Port]; ok {
+			votes[family] += 2
+		}
+		text := strings.ToLower(port.Banner + " " + port.Version + " " + port.HTTPServer)
+		for _, hint := range osHints {
+			if strings.Contains(text, hint.text) {
+				votes[hint.family] += hint.votes
+			}
+		}
+	}
+
+	best, bestVotes, tied := OSUnknown, 0, false
+	for _, family := range []string{OSLinux, OSWindows, OSNetwork} {
+		switch n := votes[family]; {
+		case n > bestVotes:
+			best, bestVotes, tied = family, n, false
+		case n > 0 && n == bestVotes:
+			tied = true
+		}
+	}
+	if tied {
+		return OSUnknown
+	}
+	return best
+}
+
+// ttlFamily returns the OS family whose initial TTL a reply arriving with
+// ttl most likely started from, or "" if the socket didn't report it
+func ttlFamily(ttl int) string {
+	switch {
+	case ttl <= 0:
+		return ""
+	case ttl <= 64:
+		return OSLinux
+	case ttl <= 128:
+		return OSWindows
+	default:
+		return OSNetwork
+	}
+}
+
+// detectOS fills in host's OS when OS detection is enabled
+func (s *Scanner) detectOS(ctx context.Context, host *models.HostResult) {
+	if !s.cfg.OSDetection || !host.Alive {
+		return
+	}
+	host.OS = s.guessOS(ctx, host.IP, host.Ports)
+}
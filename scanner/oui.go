@@ -0,0 +1,229 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OUIVendors maps the organizationally unique identifiers (the first three
+// octets of a MAC address, as six uppercase hex digits) of common network,
+// server, virtualization and consumer hardware to their vendor. It's a
+// trimmed copy of the IEEE registry; load the full list with LoadOUIFile.
+var OUIVendors = map[string]string{
+	// Virtualization
+	"000569": "VMware",
+	"000C29": "VMware",
+	"001C14": "VMware",
+	"005056": "VMware",
+	"080027": "VirtualBox",
+	"00155D": "Microsoft Hyper-V",
+	"00163E": "Xen",
+	"001C42": "Parallels",
+	"525400": "QEMU/KVM",
+
+	// Single-board computers and IoT
+	"B827EB": "Raspberry Pi Foundation",
+	"DCA632": "Raspberry Pi Trading",
+	"E45F01": "Raspberry Pi Trading",
+	"D83ADD": "Raspberry Pi Trading",
+	"2CCF67": "Raspberry Pi Trading",
+	"18FE34": "Espressif",
+	"240AC4": "Espressif",
+	"30AEA4": "Espressif",
+	"5CCF7F": "Espressif",
+	"84F3EB": "Espressif",
+	"A4CF12": "Espressif",
+
+	// Network equipment
+	"00000C": "Cisco",
+	"000142": "Cisco",
+	"000143": "Cisco",
+	"00180A": "Cisco Meraki",
+	"000585": "Juniper Networks",
+	"0010DB": "Juniper Networks",
+	"00121E": "Juniper Networks",
+	"000C42": "MikroTik",
+	"4C5E0C": "MikroTik",
+	"6C3B6B": "MikroTik",
+	"D4CA6D": "MikroTik",
+	"E48D8C": "MikroTik",
+	"00156D": "Ubiquiti",
+	"002722": "Ubiquiti",
+	"0418D6": "Ubiquiti",
+	"24A43C": "Ubiquiti",
+	"44D9E7": "Ubiquiti",
+	"788A20": "Ubiquiti",
+	"802AA8": "Ubiquiti",
+	"F09FC2": "Ubiquiti",
+	"FCECDA": "Ubiquiti",
+	"00090F": "Fortinet",
+	"085B0E": "Fortinet",
+	"001B17": "Palo Alto Networks",
+	"000B86": "Aruba Networks",
+	"001A1E": "Aruba Networks",
+	"00E0FC": "Huawei",
+	"001882": "Huawei",
+	"00095B": "Netgear",
+	"000FB5": "Netgear",
+	"00146C": "Netgear",
+	"001B2F": "Netgear",
+	"00223F": "Netgear",
+	"50C7BF": "TP-Link",
+	"14CC20": "TP-Link",
+	"F4F26D": "TP-Link",
+	"00040E": "AVM",
+	"001C4A": "AVM",
+	"3810D5": "AVM",
+
+	// Servers and PCs
+	"00065B": "Dell",
+	"000874": "Dell",
+	"000BDB": "Dell",
+	"00123F": "Dell",
+	"001422": "Dell",
+	"00188B": "Dell",
+	"00219B": "Dell",
+	"0024E8": "Dell",
+	"000BCD": "Hewlett Packard",
+	"000F20": "Hewlett Packard",
+	"00110A": "Hewlett Packard",
+	"001321": "Hewlett Packard",
+	"001438": "Hewlett Packard",
+	"0017A4": "Hewlett Packard",
+	"001B78": "Hewlett Packard",
+	"002590": "Supermicro",
+	"0CC47A": "Supermicro",
+	"AC1F6B": "Supermicro",
+	"0002B3": "Intel",
+	"000E0C": "Intel",
+	"001320": "Intel",
+	"001517": "Intel",
+	"001B21": "Intel",
+	"00E04C": "Realtek",
+	"001018": "Broadcom",
+	"001132": "Synology",
+
+	// Consumer devices
+	"000393": "Apple",
+	"000A95": "Apple",
+	"0017F2": "Apple",
+	"001EC2": "Apple",
+	"002500": "Apple",
+	"3C0754": "Apple",
+	"ACBC32": "Apple",
+	"F01898": "Apple",
+	"0000F0": "Samsung",
+	"001247": "Samsung",
+	"001599": "Samsung",
+	"3C5AB4": "Google",
+	"F4F5D8": "Google",
+	"546009": "Google",
+	"44650D": "Amazon",
+	"74C246": "Amazon",
+	"F0D2F1": "Amazon",
+	"000E58": "Sonos",
+	"5CAAFD": "Sonos",
+	"949F3E": "Sonos",
+	"001BA9": "Brother",
+	"000085": "Canon",
+	"00408C": "Axis Communications",
+	"ACCC8E": "Axis Communications",
+	"4419B6": "Hikvision",
+	"BCAD28": "Hikvision",
+}
+
+// ouis is the table loaded by LoadOUIFile
+var ouis struct {
+	sync.RWMutex
+	vendors map[string]string
+}
+
+// LoadOUIFile reads an OUI registry, either the IEEE's oui.txt or a
+// Wireshark manuf file, and makes it the table LookupVendor consults first,
+// replacing any loaded before. Lines that don't start with a three-octet
+// prefix, such as oui.txt's address lines or manuf's longer MA-M and MA-S
+// prefixes, are skipped, as are # comments. Where a prefix appears more than
+// once the first entry wins.
+func LoadOUIFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	table := make(map[string]string)
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		text, _, _ := strings.Cut(lines.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			continue
+		}
+		prefix, ok := ouiPrefix(fields[0])
+		if !ok {
+			continue
+		}
+
+		// oui.txt: "00-00-0C   (hex)		Cisco Systems, Inc"; manuf:
+		// "00:00:0C	Cisco	Cisco Systems, Inc", where the full name is last
+		rest := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(rest, "(hex)"), "(base 16)"))
+		names := strings.Split(rest, "\t")
+		vendor := strings.TrimSpace(names[len(names)-1])
+		if vendor == "" {
+			continue
+		}
+		if _, dup := table[prefix]; !dup {
+			table[prefix] = vendor
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(table) == 0 {
+		return fmt.Errorf("%s: no OUI entries found", path)
+	}
+
+	ouis.Lock()
+	ouis.vendors = table
+	ouis.Unlock()
+	return nil
+}
+
+// LookupVendor returns the vendor of the network interface with MAC address
+// mac, in any form net.ParseMAC accepts, or "" if its prefix isn't known. It
+// consults the table loaded with LoadOUIFile, then the built-in OUIVendors.
+func LookupVendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	prefix := fmt.Sprintf("%02X%02X%02X", hw[0], hw[1], hw[2])
+
+	ouis.RLock()
+	vendor := ouis.vendors[prefix]
+	ouis.RUnlock()
+	if vendor != "" {
+		return vendor
+	}
+	return OUIVendors[prefix]
+}
+
+// ouiPrefix returns s, a three-octet prefix such as "00:1A:2B",
+// "00-1a-2b" or "001A2B", as six uppercase hex digits
+func ouiPrefix(s string) (string, bool) {
+	s = strings.NewReplacer(":", "", "-", "", ".", "").Replace(s)
+	if len(s) != 6 {
+		return "", false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return "", false
+		}
+	}
+	return strings.ToUpper(s), true
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"netscan/models"
+	"netscan/utils"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HostPort is a single host/port combination to scan
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// LoadHostPortPairs reads host,port pairs from a CSV file. Blank lines,
+// lines starting with # and a leading "host,port" header are skipped.
+func LoadHostPortPairs(path string) ([]HostPort, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var pairs []HostPort
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		if len(record) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected host,port but got %d fields", path, line, len(record))
+		}
+
+		host := strings.TrimSpace(record[0])
+		port, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			// Allow a header row such as "host,port"
+			if len(pairs) == 0 && line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("%s:%d: invalid port %q", path, line, record[1])
+		}
+		if host == "" || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("%s:%d: invalid pair %q,%q", path, line, record[0], record[1])
+		}
+
+		pairs = append(pairs, HostPort{Host: host, Port: port})
+	}
+
+	return pairs, nil
+}
+
+// ScanPairs scans exactly the given host/port combinations using the default
+// configuration
+func ScanPairs(pairs []HostPort) []models.HostResult {
+	return New(DefaultConfig()).ScanPairs(pairs)
+}
+
+// ScanPairs scans exactly the given host/port combinations rather than the
+// cross product of hosts and ports. Only hosts with at least one open port
+// are returned, unless IncludeClosed is set.
+func (s *Scanner) ScanPairs(pairs []HostPort) []models.HostResult {
+	const maxConcurrent = 100
+
+	type pairResult struct {
+		host   string
+		result models.PortResult
+	}
+
+	var wg sync.WaitGroup
+	var collector ResultCollector[pairResult]
+	sem := make(chan struct{}, maxConcurrent)
+
+	// Separate per-host limits so one host with many pairs can't take all
+	// of the connections
+	hostSems := make(map[string]chan struct{})
+	for _, pair := range pairs {
+		if _, ok := hostSems[pair.Host]; !ok {
+			hostSems[pair.Host] = make(chan struct{}, s.hostConcurrency(maxConcurrent))
+		}
+	}
+
+	for _, pair := range pairs {
+		wg.Add(1)
+		go func(pair HostPort) {
+			defer wg.Done()
+			hostSem := hostSems[pair.Host]
+			hostSem <- struct{}{}
+			defer func() { <-hostSem }()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := s.scanPortFast(context.Background(), pair.Host, pair.Port)
+			if result.Open || s.cfg.IncludeClosed {
+				collector.Add(pairResult{host: pair.Host, result: result})
+			}
+		}(pair)
+	}
+	wg.Wait()
+
+	byHost := make(map[string][]models.PortResult)
+	for _, r := range collector.Snapshot() {
+		byHost[r.host] = append(byHost[r.host], r.result)
+	}
+
+	hosts := make([]models.HostResult, 0, len(byHost))
+	for ip, ports := range byHost {
+		host := models.HostResult{
+			IP:     ip,
+			Ports:  ports,
+			Status: models.StatusOpenPorts,
+		}
+		if host.OpenCount() == 0 {
+			host.Status = models.StatusNoPorts
+		}
+		// A reset still proves the host is up
+		for _, port := range ports {
+			if port.Open || port.State == models.StateClosed {
+				host.Alive = true
+			}
+		}
+		s.capPorts(&host)
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		return utils.CompareIPs(hosts[i].IP, hosts[j].IP)
+	})
+
+	return hosts
+}
@@ -0,0 +1,579 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"netscan/banner"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyOpenFiles is the Error recorded on ports that couldn't be probed
+// because the process ran out of file descriptors, even after ScanPorts
+// backed off. Lower MaxConcurrency or raise ulimit -n.
+var ErrTooManyOpenFiles = errors.New("too many open files")
+
+// Out-of-descriptor dials are retried up to fdRetries times, waiting
+// fdBackoff before the first retry and twice as long before each one after,
+// up to fdMaxBackoff
+const (
+	fdRetries    = 10
+	fdBackoff    = 50 * time.Millisecond
+	fdMaxBackoff = time.Second
+)
+
+// ScanPorts scans ports on target using the default configuration
+func ScanPorts(target string, ports []int) []models.PortResult {
+	return New(DefaultConfig()).ScanPorts(target, ports)
+}
+
+// ScanPorts scans ports on target, prints the open ones and returns them
+// sorted by port number
+func (s *Scanner) ScanPorts(target string, ports []int) []models.PortResult {
+	return s.ScanPortsContext(context.Background(), target, ports)
+}
+
+// ScanPortsContext is ScanPorts, stopping early when ctx ends: no new ports
+// are started, dials in flight are abandoned, and the ports found so far
+// are printed and returned. Every goroutine has exited by the time it
+// returns, though banner grabs already under way can delay that by up to
+// their timeout.
+func (s *Scanner) ScanPortsContext(ctx context.Context, target string, ports []int) []models.PortResult {
+	fmt.Fprintf(s.out, "\n🔍 Scanning %s for %d ports...\n", target, len(ports))
+
+	var allResults []models.PortResult
+	open, failed := 0, 0
+
+	start := time.Now()
+
+	for result := range s.ScanPortsStream(ctx, target, ports) {
+		allResults = append(allResults, result)
+		if result.Open {
+			open++
+		}
+		if result.State == models.StateError {
+			failed++
+		}
+	}
+
+	elapsed := time.Since(start)
+
+	sort.Slice(allResults, func(i, j int) bool {
+		return allResults[i].Port < allResults[j].Port
+	})
+
+	if ctx.Err() != nil {
+		fmt.Fprintf(s.out, "\n⚠️  Scan interrupted after %v, results are partial\n", elapsed)
+	} else {
+		fmt.Fprintf(s.out, "\n✅ Scan completed in %v\n", elapsed)
+	}
+	if failed > 0 {
+		fmt.Fprintf(s.out, "⚠️  %d ports couldn't be probed (%v), results are incomplete\n", failed, ErrTooManyOpenFiles)
+	}
+	fmt.Fprintf(s.out, "📊 Found %d open ports:\n\n", open)
+
+	for _, port := range allResults {
+		s.printPort(s.out, port)
+	}
+
+	return allResults
+}
+
+// ScanPortsStream scans ports on target using the default configuration,
+// sending results on the returned channel as they're found
+func ScanPortsStream(ctx context.Context, target string, ports []int) <-chan models.PortResult {
+	return New(DefaultConfig()).ScanPortsStream(ctx, target, ports)
+}
+
+// ScanPortsStream scans ports on target like ScanPortsFunc, but sends each
+// result on the returned channel as soon as it's found, for progress
+// displays and consumers that stop early. Results arrive in the order
+// they're found, not sorted, and aren't printed. The channel is closed when
+// the scan is over or ctx ends; a consumer that stops reading early must
+// cancel ctx to let the scan wind down.
+func (s *Scanner) ScanPortsStream(ctx context.Context, target string, ports []int) <-chan models.PortResult {
+	results := make(chan models.PortResult, s.portConcurrency())
+	go func() {
+		defer close(results)
+		s.scanPortsFunc(ctx, target, ports, func(result models.PortResult) {
+			send(ctx, results, result)
+		})
+	}()
+	return results
+}
+
+// send sends v on ch, giving up if ctx ends while ch is full. A result that
+// fits is sent even after ctx ends, so a consumer still draining ch sees
+// everything that was found.
+func send[T any](ctx context.Context, ch chan<- T, v T) {
+	select {
+	case ch <- v:
+		return
+	default:
+	}
+	select {
+	case ch <- v:
+	case <-ctx.Done():
+	}
+}
+
+// ScanPortsTo scans ports on target and writes each open port to w as soon as
+// it's found, in discovery order. Nothing is buffered, so memory use stays
+// constant even for a full 65535-port scan. It returns the number of open
+// ports found.
+func (s *Scanner) ScanPortsTo(w io.Writer, target string, ports []int) int {
+	found := 0
+	s.ScanPortsFunc(target, ports, func(result models.PortResult) {
+		s.printPort(s.plain(w), result)
+		if result.Open {
+			found++
+		}
+	})
+	return found
+}
+
+// ScanPortsFunc scans ports on target with a fixed pool of workers and calls
+// fn for each open port as soon as it's found, and for each port that
+// couldn't be probed (State StateError), plus closed and filtered ports
+// with IncludeClosed. When dials run out of file descriptors, they are
+// retried after a pause and workers retire one at a time until the rest fit
+// in what the system can give. Results pass through a
+// bounded channel to a single consumer, so fn never runs concurrently with
+// itself and memory use doesn't grow with the size of the port list.
+// Nothing is scanned on a target on the exclusion list.
+func (s *Scanner) ScanPortsFunc(target string, ports []int, fn func(models.PortResult)) {
+	s.scanPortsFunc(context.Background(), target, ports, fn)
+}
+
+// scanPortsFunc is ScanPortsFunc, skipping any ports not yet started when
+// ctx ends
+func (s *Scanner) scanPortsFunc(ctx context.Context, target string, ports []int, fn func(models.PortResult)) {
+	if s.excluded(target) {
+		fmt.Fprintf(s.out, "⛔ %s is excluded from scanning\n", target)
+		return
+	}
+
+	const batchSize = 1000 // Progress is reported every batchSize ports
+	workers := s.portConcurrency()
+	if len(ports) < workers {
+		workers = len(ports)
+	}
+
+	jobs := make(chan int, workers)
+	results := make(chan models.PortResult, workers)
+
+	go func() {
+		defer close(jobs)
+		for _, port := range scanOrder(s, ports) {
+			select {
+			case jobs <- port:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Each dial needs one of the slots, see scanPortRetryingFDs
+	slots := make(chan struct{}, workers)
+	for i := 0; i < workers; i++ {
+		slots <- struct{}{}
+	}
+	var live atomic.Int64
+	live.Store(int64(workers))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range jobs {
+				result := s.scanPortRetryingFDs(ctx, target, port, slots, &live)
+				if !result.Open && ctx.Err() != nil {
+					// The dial was abandoned, so the state says nothing
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	batches := (len(ports) + batchSize - 1) / batchSize
+	processed := 0
+	for result := range results {
+		processed++
+		if result.Open || result.State == models.StateError || s.cfg.IncludeClosed {
+			fn(result)
+		}
+		if processed%batchSize == 0 || processed == len(ports) {
+			fmt.Fprintf(s.out, "📈 Processed batch %d/%d\n", (processed+batchSize-1)/batchSize, batches)
+		}
+	}
+	if n := live.Load(); n < int64(workers) {
+		fmt.Fprintf(s.out, "⚠️  Ran out of file descriptors, concurrency reduced from %d to %d\n", workers, n)
+	}
+}
+
+// scanPortRetryingFDs is scanPort run while holding one of slots, retrying
+// with a growing pause while the dial fails for lack of file descriptors.
+// Each such failure keeps its slot for good, unless it's the last of the
+// live ones, so concurrency shrinks until the dials fit. Waiting workers
+// get slots in turn, so the retries aren't starved by fresh ports.
+func (s *Scanner) scanPortRetryingFDs(ctx context.Context, target string, port int, slots chan struct{}, live *atomic.Int64) models.PortResult {
+	backoff := fdBackoff
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-slots:
+		case <-ctx.Done():
+			return models.PortResult{Port: port, Protocol: models.ProtoTCP}
+		}
+
+		result := s.scanPort(ctx, target, port)
+		if result.State != models.StateError || attempt == fdRetries {
+			slots <- struct{}{}
+			return result
+		}
+		if !retire(live) {
+			slots <- struct{}{}
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result
+		}
+		backoff = min(2*backoff, fdMaxBackoff)
+	}
+}
+
+// retire takes a slot out of the live count unless it's the last one,
+// reporting whether it did
+func retire(live *atomic.Int64) bool {
+	for {
+		n := live.Load()
+		if n <= 1 {
+			return false
+		}
+		if live.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// ScanPort scans a single port using the default configuration
+func ScanPort(host string, port int) models.PortResult {
+	return New(DefaultConfig()).ScanPort(host, port)
+}
+
+// ScanPort connects to a single port and grabs its banner if it's open
+func (s *Scanner) ScanPort(host string, port int) models.PortResult {
+	return s.scanPort(context.Background(), host, port)
+}
+
+// scanPort is ScanPort, abandoning the dial if ctx ends
+func (s *Scanner) scanPort(ctx context.Context, host string, port int) models.PortResult {
+	host = utils.BareHost(host)
+	target := hostPort(host, port)
+
+	conn, latency, err := s.dialRetrying(ctx, target, thoroughDialFactor*s.dialTimeout())
+	if err != nil {
+		return dialFailure(port, err)
+	}
+	defer s.closeConn(conn)
+	conn = s.throttle(conn)
+
+	result := models.PortResult{
+		Port:     port,
+		Protocol: models.ProtoTCP,
+		Open:     true,
+		State:    models.StateOpen,
+		Service:  s.serviceName(port),
+		Latency:  latency,
+	}
+
+	opts := s.bannerOptions(banner.DefaultOptions)
+	if banner.TLSPorts[port] {
+		s.grabTLS(conn, host, port, opts, &result)
+		s.fingerprintHTTP(ctx, target, host, opts, &result)
+		return result
+	}
+
+	grabbed := banner.Grab(conn, port, opts)
+	s.setBanner(&result, grabbed)
+
+	// A service that says nothing in plaintext, or answers with a TLS
+	// alert, may be waiting for a handshake
+	if grabbed.Banner == "" || banner.IsTLSAlert(grabbed.Banner) {
+		s.retryTLS(ctx, target, host, port, opts, &result)
+	}
+	s.fingerprintHTTP(ctx, target, host, opts, &result)
+	return result
+}
+
+// fingerprintHTTP fills in the HTTP fields of a port whose banner shows it
+// speaks HTTP, making fresh requests over TLS if the banner came that way
+func (s *Scanner) fingerprintHTTP(ctx context.Context, target, host string, opts banner.Options, result *models.PortResult) {
+	if !strings.HasPrefix(result.Banner, "HTTP/") {
+		return
+	}
+
+	useTLS := result.TLS != nil && result.TLS.Completed
+	var conns []net.Conn
+	defer func() {
+		for _, conn := range conns {
+			s.closeConn(conn)
+		}
+	}()
+	dial := func() (net.Conn, error) {
+		conn, err := s.dial(ctx, target, s.dialTimeout())
+		if err != nil {
+			return nil, err
+		}
+		conns = append(conns, conn)
+		conn = s.throttle(conn)
+		if !useTLS {
+			return conn, nil
+		}
+		tlsConn, _, err := banner.Handshake(conn, host, s.cfg.ClientCert, opts.Timeout)
+		return tlsConn, err
+	}
+
+	info, err := banner.FingerprintHTTP(dial, target, opts.Timeout)
+	if err != nil {
+		return
+	}
+	result.HTTPStatus = info.Status
+	result.HTTPServer = info.Server
+	result.HTTPTitle = info.Title
+	if result.Version == "" && info.Server != "" {
+		// The banner may have been cut short of the Server header
+		result.Version = banner.ParseVersion("HTTP", "Server: "+info.Server)
+	}
+}
+
+// grabTLS performs a TLS handshake on conn and grabs the banner through it,
+// recording the outcome in result. It reports whether the service speaks
+// TLS, which is also true when the handshake failed over a client
+// certificate.
+func (s *Scanner) grabTLS(conn net.Conn, host string, port int, opts banner.Options, result *models.PortResult) bool {
+	tlsConn, info, err := banner.Handshake(conn, host, s.cfg.ClientCert, opts.Timeout)
+	result.TLS = info
+	result.TLSOnly = info != nil
+	if err != nil {
+		return info != nil
+	}
+
+	s.setBanner(result, banner.Grab(tlsConn, port, opts))
+	return true
+}
+
+// retryTLS reconnects to a port whose plaintext probe came up empty and
+// tries TLS instead. If the handshake works, result is replaced with what
+// came over TLS.
+func (s *Scanner) retryTLS(ctx context.Context, target, host string, port int, opts banner.Options, result *models.PortResult) {
+	conn, err := s.dial(ctx, target, thoroughDialFactor*s.dialTimeout())
+	if err != nil {
+		return
+	}
+	defer s.closeConn(conn)
+
+	tlsResult := *result
+	if s.grabTLS(s.throttle(conn), host, port, opts, &tlsResult) {
+		*result = tlsResult
+	}
+}
+
+// bannerProtocols maps the names of services banner identification can
+// tell apart, and their variants, to the protocol they speak, in upper case
+var bannerProtocols = map[string]string{
+	"SSH":        "SSH",
+	"HTTP":       "HTTP",
+	"HTTP-ALT":   "HTTP",
+	"HTTPS":      "HTTP",
+	"HTTPS-ALT":  "HTTP",
+	"FTP":        "FTP",
+	"SMTP":       "SMTP",
+	"SMTPS":      "SMTP",
+	"SUBMISSION": "SMTP",
+	"POP3":       "POP3",
+	"POP3S":      "POP3",
+	"IMAP":       "IMAP",
+	"IMAPS":      "IMAP",
+	"VNC":        "VNC",
+	"REDIS":      "REDIS",
+	"MEMCACHED":  "MEMCACHED",
+	"MYSQL":      "MYSQL",
+	"AMQP":       "AMQP",
+	"AMQPS":      "AMQP",
+}
+
+// setBanner copies a banner grab into result. A service the banner gives
+// away replaces the name the port goes by when that's unknown or names a
+// different protocol banners can identify, e.g. SSH on the FTP port. Names
+// of variants (HTTP-Alt for HTTP), of services built on another protocol
+// (Elasticsearch over HTTP) and from Services are kept.
+func (s *Scanner) setBanner(result *models.PortResult, grabbed banner.Result) {
+	result.Banner = grabbed.Banner
+	if detected := grabbed.Service; detected != "" {
+		if detected == "HTTP" && result.TLS != nil && result.TLS.Completed {
+			detected = "HTTPS"
+		}
+		_, named := s.cfg.Services[result.Port]
+		protocol, identifiable := bannerProtocols[strings.ToUpper(result.Service)]
+		disagrees := identifiable && protocol != bannerProtocols[strings.ToUpper(detected)]
+		if !named && (result.Service == "" || disagrees) {
+			result.Service = detected
+		}
+	}
+	result.Version = banner.ParseVersion(result.Service, grabbed.Banner)
+	result.Unresponsive = grabbed.Silent
+	result.SecurityHeaders = grabbed.SecurityHeaders
+}
+
+// printPort writes a single port line
+func (s *Scanner) printPort(w io.Writer, port models.PortResult) {
+	service := port.Service
+	if service == "" {
+		service = "Unknown"
+	}
+	label := strconv.Itoa(port.Port)
+	if port.Protocol == models.ProtoUDP {
+		label += "/udp"
+	}
+	fmt.Fprintf(w, "%s Port %-5s %-12s", stateMarker(port), label, service)
+	if port.Version != "" {
+		fmt.Fprintf(w, " [%s]", port.Version)
+	}
+	switch {
+	case port.Error != "":
+		fmt.Fprintf(w, " (%s: %s)", port.State, port.Error)
+	case !port.Open:
+		fmt.Fprintf(w, " (%s)", port.State)
+	}
+	if port.TLSOnly {
+		fmt.Fprintf(w, " (TLS only)")
+	}
+	if port.Unresponsive {
+		fmt.Fprintf(w, " (no service response)")
+	}
+	if note := clientCertNote(port); note != "" {
+		fmt.Fprintf(w, " (%s)", note)
+	}
+	if port.Banner != "" {
+		fmt.Fprintf(w, " - %s", s.displayBanner(port.Banner))
+	}
+	fmt.Fprintln(w)
+	if note := headerNote(port); note != "" {
+		fmt.Fprintf(w, "   🛡️  %s\n", note)
+	}
+	if note := httpNote(port); note != "" {
+		fmt.Fprintf(w, "   🌐 %s\n", note)
+	}
+	if note := certNote(port, time.Now()); note != "" {
+		fmt.Fprintf(w, "   🔒 %s\n", note)
+	}
+}
+
+// httpNote describes an HTTP port's fingerprint, e.g. 200 nginx "Welcome",
+// or returns "" if the port wasn't fingerprinted
+func httpNote(port models.PortResult) string {
+	if port.HTTPStatus == 0 {
+		return ""
+	}
+	note := strconv.Itoa(port.HTTPStatus)
+	if port.HTTPServer != "" {
+		note += " " + port.HTTPServer
+	}
+	if port.HTTPTitle != "" {
+		note += fmt.Sprintf(" %q", port.HTTPTitle)
+	}
+	return note
+}
+
+// certExpiryWarning is how close to expiry a certificate gets flagged
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// certNote describes a port's TLS certificate, flagging one that has
+// expired or expires within certExpiryWarning of now
+func certNote(port models.PortResult, now time.Time) string {
+	if port.TLS == nil || port.TLS.NotAfter.IsZero() {
+		return ""
+	}
+	info := port.TLS
+	note := info.Subject
+	if len(info.SANs) > 0 {
+		note += " [" + strings.Join(info.SANs, ", ") + "]"
+	}
+	note += ", issued by " + info.Issuer
+	switch left := info.NotAfter.Sub(now); {
+	case left <= 0:
+		note += ", EXPIRED " + info.NotAfter.Format("2006-01-02")
+	case left < certExpiryWarning:
+		note += fmt.Sprintf(", expires %s (in %d days)", info.NotAfter.Format("2006-01-02"), int(left.Hours()/24))
+	default:
+		note += ", expires " + info.NotAfter.Format("2006-01-02")
+	}
+	return note
+}
+
+// clientCertNote describes how a TLS port treated the client certificate,
+// or returns "" if it didn't ask for one
+func clientCertNote(port models.PortResult) string {
+	switch {
+	case port.TLS == nil || !port.TLS.ClientCertRequested:
+		return ""
+	case port.TLS.ClientCertAccepted:
+		return "client cert accepted"
+	case port.TLS.Completed:
+		return "client cert requested"
+	default:
+		return "client cert rejected"
+	}
+}
+
+// headerNote summarizes an HTTP port's security header audit, or returns ""
+// if the port wasn't audited
+func headerNote(port models.PortResult) string {
+	if port.SecurityHeaders == nil {
+		return ""
+	}
+
+	var missing []string
+	for _, name := range banner.SecurityHeaders {
+		if _, ok := port.SecurityHeaders[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	note := fmt.Sprintf("%d/%d security headers", len(banner.SecurityHeaders)-len(missing), len(banner.SecurityHeaders))
+	if len(missing) > 0 {
+		note += " (missing " + strings.Join(missing, ", ") + ")"
+	}
+	return note
+}
+
+// displayBanner shortens a banner to BannerWidth characters for console
+// output. Results keep the full banner for the other output formats.
+func (s *Scanner) displayBanner(b string) string {
+	width := s.cfg.BannerWidth
+	runes := []rune(b)
+	if width <= 0 || len(runes) <= width {
+		return b
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
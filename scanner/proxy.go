@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// ErrProxyUDP is returned for UDP probes (SNMP, NetBIOS) when a proxy is
+// configured, since they can't be tunneled and would otherwise bypass it
+var ErrProxyUDP = errors.New("UDP probes can't be sent through a proxy")
+
+// dialProxy opens a tunnel to address through the proxy in
+// ScanConfig.Proxy, picking the protocol by its scheme
+func (s *Scanner) dialProxy(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	if err := s.checkTarget(ctx, address); err != nil {
+		return nil, err
+	}
+	switch s.cfg.Proxy.Scheme {
+	case "socks5", "socks5h":
+		return s.dialSOCKS(ctx, address, timeout)
+	}
+	return s.dialConnect(ctx, address, timeout)
+}
+
+// dialConnect opens a tunnel to address through an HTTP proxy with a
+// CONNECT request. The proxy does the connecting, so a port that's closed
+// and one that's filtered both just fail.
+func (s *Scanner) dialConnect(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	proxy := s.cfg.Proxy
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), "8080")
+	}
+
+	conn, err := s.dialDirect(ctx, "tcp", proxyAddr, timeout)
+	if err != nil {
+		return nil, unreachableProxy(err)
+	}
+
+	// The whole exchange shares the dial timeout
+	conn.SetDeadline(time.Now().Add(timeout))
+	req := "CONNECT " + address + " HTTP/1.1\r\nHost: " + address + "\r\n"
+	if user := proxy.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: CONNECT %s: %s", address, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	if br.Buffered() > 0 {
+		// The service spoke before we read past the proxy's response
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// dialSOCKS opens a connection to address through a SOCKS5 proxy. Host
+// names are passed to the proxy to resolve. Unlike an HTTP proxy, a SOCKS5
+// proxy says when the target refused the connection, so closed ports still
+// read as closed.
+func (s *Scanner) dialSOCKS(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	proxy := s.cfg.Proxy
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), "1080")
+	}
+	var auth *xproxy.Auth
+	if user := proxy.User; user != nil {
+		password, _ := user.Password()
+		auth = &xproxy.Auth{User: user.Username(), Password: password}
+	}
+
+	// The whole exchange shares the dial timeout
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	forward := &socksForward{s: s, timeout: timeout}
+	d, err := xproxy.SOCKS5("tcp", proxyAddr, auth, forward)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	conn, err := d.(xproxy.ContextDialer).DialContext(ctx, "tcp", address)
+	switch {
+	case err == nil:
+		return conn, nil
+	case forward.err != nil:
+		return nil, unreachableProxy(forward.err)
+	case strings.HasSuffix(err.Error(), "connection refused"):
+		// The proxy's "connection refused" reply, which x/net only
+		// reports as text
+		return nil, &proxyRefused{err}
+	}
+	return nil, fmt.Errorf("proxy: %w", err)
+}
+
+// socksForward connects to a SOCKS proxy for x/net/proxy with dialDirect,
+// remembering any error so it can be told apart from the proxy's replies
+type socksForward struct {
+	s       *Scanner
+	timeout time.Duration
+	err     error
+}
+
+func (f *socksForward) Dial(network, address string) (net.Conn, error) {
+	return f.DialContext(context.Background(), network, address)
+}
+
+func (f *socksForward) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := f.s.dialDirect(ctx, network, address, f.timeout)
+	f.err = err
+	return conn, err
+}
+
+// unreachableProxy wraps a failure to connect to the proxy itself. The
+// proxy refusing the connection says nothing about the target, so that
+// doesn't unwrap to a refusal.
+func unreachableProxy(err error) error {
+	if isRefused(err) {
+		return fmt.Errorf("proxy: %v", err)
+	}
+	return fmt.Errorf("proxy: %w", err)
+}
+
+// proxyRefused is a proxy's report that the target refused the connection.
+// It matches syscall.ECONNREFUSED so the port is classed as closed.
+type proxyRefused struct {
+	err error
+}
+
+func (e *proxyRefused) Error() string {
+	return "proxy: " + e.err.Error()
+}
+
+func (e *proxyRefused) Unwrap() []error {
+	return []error{e.err, syscall.ECONNREFUSED}
+}
+
+// Proxied reports whether the scanner's TCP connections go through a Proxy
+func (s *Scanner) Proxied() bool {
+	return s.cfg.Proxy != nil
+}
+
+// checkTarget applies the exclusion list to a target that a proxy or a
+// custom Dialer will connect to. Host names are resolved locally to check
+// them, and refused if that fails, since the answer the connection ends up
+// using can't be checked.
+func (s *Scanner) checkTarget(ctx context.Context, address string) error {
+	if len(s.cfg.Exclude) == 0 {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addrs := []string{host}
+	if net.ParseIP(host) == nil {
+		if addrs, err = s.resolver().LookupHost(ctx, host); err != nil {
+			return fmt.Errorf("%s: checking exclusions: %w", host, err)
+		}
+	}
+	for _, addr := range addrs {
+		if s.excluded(addr) {
+			return fmt.Errorf("%s: %w", addr, ErrExcluded)
+		}
+	}
+	return nil
+}
+
+// bufferedConn is a conn whose first bytes were already read into r
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,28 @@
+package scanner
+
+import (
+	"context"
+	"netscan/models"
+	"strings"
+	"time"
+)
+
+// ptrTimeout bounds each reverse DNS lookup
+const ptrTimeout = 2 * time.Second
+
+// resolvePTR fills in host's Hostname from its PTR record when reverse
+// lookups are enabled. Hosts with no PTR record (NXDOMAIN) or a lookup that
+// fails or times out are left as they are.
+func (s *Scanner) resolvePTR(ctx context.Context, host *models.HostResult) {
+	if !s.cfg.ReverseDNS || !host.Alive || host.Hostname != "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, ptrTimeout)
+	defer cancel()
+
+	names, err := s.resolver().LookupAddr(ctx, host.IP)
+	if err != nil || len(names) == 0 {
+		return
+	}
+	host.Hostname = strings.TrimSuffix(names[0], ".")
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package scanner
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isRefused reports whether a dial failed because the target sent a reset
+func isRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isUnreachable reports whether a dial failed because there's no route to
+// the target's host or network
+func isUnreachable(err error) bool {
+	return errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH)
+}
+
+// isFDExhausted reports whether a dial failed because this process or the
+// system ran out of file descriptors
+func isFDExhausted(err error) bool {
+	return errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
+}
+
+// isPortUnreachable reports whether a read on a connected UDP socket failed
+// because the target answered with ICMP port unreachable
+func isPortUnreachable(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
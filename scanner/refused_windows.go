@@ -0,0 +1,43 @@
+//go:build windows
+
+package scanner
+
+import (
+	"errors"
+	"syscall"
+)
+
+// WSAECONNREFUSED, WSAECONNRESET, WSAEMFILE, WSAENOBUFS, WSAEHOSTUNREACH
+// and WSAENETUNREACH, which syscall doesn't define on Windows
+const (
+	wsaeconnrefused = syscall.Errno(10061)
+	wsaeconnreset   = syscall.Errno(10054)
+	wsaemfile       = syscall.Errno(10024)
+	wsaenobufs      = syscall.Errno(10055)
+	wsaehostunreach = syscall.Errno(10065)
+	wsaenetunreach  = syscall.Errno(10051)
+)
+
+// isRefused reports whether a dial failed because the target sent a reset
+func isRefused(err error) bool {
+	return errors.Is(err, wsaeconnrefused) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isUnreachable reports whether a dial failed because there's no route to
+// the target's host or network
+func isUnreachable(err error) bool {
+	return errors.Is(err, wsaehostunreach) || errors.Is(err, wsaenetunreach)
+}
+
+// isFDExhausted reports whether a dial failed because this process ran out
+// of sockets. Windows reports running out of socket buffers the same way.
+func isFDExhausted(err error) bool {
+	return errors.Is(err, wsaemfile) || errors.Is(err, wsaenobufs)
+}
+
+// isPortUnreachable reports whether a read on a connected UDP socket failed
+// because the target answered with ICMP port unreachable, which Windows
+// reports as a connection reset
+func isPortUnreachable(err error) bool {
+	return errors.Is(err, wsaeconnreset) || isRefused(err)
+}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"netscan/models"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// topPorts ranks the ports in CommonServices from most to least often found
+// open on real networks
+var topPorts = []int{
+	80, 23, 443, 21, 22, 25, 3389, 110, 445, 139,
+	143, 53, 135, 3306, 8080, 1723, 111, 995, 993, 5900,
+	587, 8888, 465, 548, 5060, 179, 8443, 8000, 554, 1433,
+	515, 631, 8081, 2049, 88, 389, 636, 5432, 1521, 6379,
+	9100, 1080, 3128, 873, 119, 20, 1194, 6667, 9090, 5222,
+	27017, 9200, 11211, 5985, 5986, 2375, 2376, 6443, 10250, 2379,
+	2181, 9092, 5672, 5671, 15672, 1883, 8086, 8500, 5601, 5984,
+	9042, 9300, 9418, 3690, 3268, 4369, 7001,
+}
+
+// TopPorts returns the n most common ports named in CommonServices, most
+// common first, or all of them if there are fewer than n
+func TopPorts(n int) []int {
+	n = min(max(n, 0), len(topPorts))
+	return append([]int(nil), topPorts[:n]...)
+}
+
+// LoadServiceMap reads a JSON file mapping port numbers to service names,
+// such as {"7700": "OrderService", "7701": "BillingService"}, and returns
+// CommonServices with those entries laid over it. Set the result as
+// ScanConfig.Services so internal ports show up by name instead of as
+// Unknown. CommonServices itself is left untouched.
+func LoadServiceMap(path string) (map[int]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var custom map[int]string
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	services := make(map[int]string, len(CommonServices)+len(custom))
+	for port, name := range CommonServices {
+		services[port] = name
+	}
+	for port, name := range custom {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("%s: invalid port %d", path, port)
+		}
+		services[port] = name
+	}
+	return services, nil
+}
+
+// services is the table loaded by LoadServicesFromFile, keyed by protocol
+// and then port
+var services struct {
+	sync.RWMutex
+	byProto map[string]map[int]string
+}
+
+// LoadServicesFromFile reads a services database in the /etc/services
+// format, one "name port/protocol [aliases...]" entry per line with #
+// comments, and makes it the table LookupService consults first, replacing
+// any loaded before. Point it at /etc/services to name ports from the full
+// IANA list. Where a port/protocol appears more than once the first entry
+// wins, as with getservbyport(3).
+func LoadServicesFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	table := make(map[string]map[int]string)
+	lines := bufio.NewScanner(f)
+	for n := 1; lines.Scan(); n++ {
+		text, _, _ := strings.Cut(lines.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("%s:%d: missing port/protocol", path, n)
+		}
+
+		portText, proto, ok := strings.Cut(fields[1], "/")
+		port, err := strconv.Atoi(portText)
+		if !ok || err != nil || port < 0 || port > 65535 {
+			return fmt.Errorf("%s:%d: invalid port/protocol %q", path, n, fields[1])
+		}
+		proto = strings.ToLower(proto)
+		if table[proto] == nil {
+			table[proto] = make(map[int]string)
+		}
+		if _, dup := table[proto][port]; !dup {
+			table[proto][port] = fields[0]
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	services.Lock()
+	services.byProto = table
+	services.Unlock()
+	return nil
+}
+
+// LookupService returns the name of the service usually found on port over
+// proto ("tcp" or "udp", "" meaning tcp), or "" if it isn't known. It
+// consults the table loaded with LoadServicesFromFile, then the built-in
+// CommonServices or UDPServices.
+func LookupService(port int, proto string) string {
+	proto = strings.ToLower(proto)
+	if proto == "" {
+		proto = models.ProtoTCP
+	}
+
+	services.RLock()
+	name := services.byProto[proto][port]
+	services.RUnlock()
+	if name != "" {
+		return name
+	}
+
+	if proto == models.ProtoUDP {
+		return UDPServices[port]
+	}
+	return CommonServices[port]
+}
+
+// serviceName returns the name of the service usually found on TCP port,
+// or "" if it isn't known. Names in ScanConfig.Services take precedence.
+func (s *Scanner) serviceName(port int) string {
+	if name := s.cfg.Services[port]; name != "" {
+		return name
+	}
+	return LookupService(port, models.ProtoTCP)
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"fmt"
+	"netscan/banner"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version returns the netscan version stamped into the binary by the Go
+// toolchain, or "(devel)" for a build outside version control
+func Version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// Settings returns the scanner's effective configuration by ScanConfig
+// field name, with defaults filled in, for recording alongside results in
+// a ScanReport. Fields left unset (false, zero or empty) are included too,
+// so the record is complete.
+func (s *Scanner) Settings() map[string]string {
+	cfg := s.cfg
+
+	resolver := "system"
+	if cfg.Resolver != nil {
+		resolver = "custom"
+	}
+	deadline := "none"
+	if !cfg.Deadline.IsZero() {
+		deadline = cfg.Deadline.Format(time.RFC3339)
+	}
+	bannerMax := "default"
+	if cfg.BannerMaxBytes > 0 {
+		bannerMax = strconv.Itoa(cfg.BannerMaxBytes)
+	}
+	retryDelay := cfg.BannerRetryDelay
+	if retryDelay <= 0 {
+		retryDelay = banner.DefaultRetryDelay
+	}
+	dialer := "default"
+	if cfg.Dialer != nil {
+		dialer = "custom"
+	}
+	proxy := "none"
+	if cfg.Proxy != nil {
+		proxy = cfg.Proxy.Redacted()
+	}
+	services := "built-in"
+	if cfg.Services != nil {
+		services = fmt.Sprintf("custom (%d entries)", len(cfg.Services))
+	}
+
+	hostConc, portConc := s.discoveryConcurrency()
+
+	return map[string]string{
+		"Detailed":           strconv.FormatBool(cfg.Detailed),
+		"IncludeClosed":      strconv.FormatBool(cfg.IncludeClosed),
+		"ResetOnClose":       strconv.FormatBool(cfg.ResetOnClose),
+		"MaxPortsPerHost":    strconv.Itoa(cfg.MaxPortsPerHost),
+		"MaxConnsPerHost":    strconv.Itoa(cfg.MaxConnsPerHost),
+		"MaxConcurrency":     strconv.Itoa(s.portConcurrency()),
+		"MaxHostConcurrency": strconv.Itoa(hostConc),
+		"MaxPortConcurrency": strconv.Itoa(portConc),
+		"DiscoveryBatchSize": strconv.Itoa(orDefault(cfg.DiscoveryBatchSize, DefaultDiscoveryBatchSize)),
+		"SweepBatchSize":     strconv.Itoa(orDefault(cfg.SweepBatchSize, DefaultSweepBatchSize)),
+		"PingTimeout":        s.pingTimeout().String(),
+		"DialTimeout":        s.dialTimeout().String(),
+		"BannerTimeout":      s.bannerTimeout().String(),
+		"TimeoutJitter":      cfg.TimeoutJitter.String(),
+		"Retries":            strconv.Itoa(cfg.Retries),
+		"PingScanPorts":      strconv.FormatBool(cfg.PingScanPorts),
+		"PingMethod":         cfg.PingMethod.String(),
+		"Deadline":           deadline,
+		"Resolver":           resolver,
+		"SourcePort":         strconv.Itoa(cfg.SourcePort),
+		"ClientCert":         strconv.FormatBool(cfg.ClientCert != nil),
+		"BannerMaxBytes":     bannerMax,
+		"BannerLinger":       cfg.BannerLinger.String(),
+		"BannerRetries":      strconv.Itoa(cfg.BannerRetries),
+		"BannerRetryDelay":   retryDelay.String(),
+		"SecurityHeaders":    strconv.FormatBool(cfg.SecurityHeaders),
+		"MaxBytesPerSec":     strconv.Itoa(cfg.MaxBytesPerSec),
+		"MaxConnsPerSec":     strconv.Itoa(cfg.MaxConnsPerSec),
+		"Randomize":          strconv.FormatBool(cfg.Randomize),
+		"Seed":               strconv.FormatUint(cfg.Seed, 10),
+		"NetBIOS":            strconv.FormatBool(cfg.NetBIOS),
+		"ReverseDNS":         strconv.FormatBool(cfg.ReverseDNS),
+		"OSDetection":        strconv.FormatBool(cfg.OSDetection),
+		"Services":           services,
+		"HoneypotThreshold":  strconv.FormatFloat(cfg.HoneypotThreshold, 'g', -1, 64),
+		"HoneypotStop":       strconv.FormatBool(cfg.HoneypotStop),
+		"Exclude":            strings.Join(cfg.Exclude, ","),
+		"Proxy":              proxy,
+		"Dialer":             dialer,
+	}
+}
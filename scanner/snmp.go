@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"netscan/models"
+	"time"
+)
+
+// DefaultCommunities are the community strings ProbeSNMP tries when none are
+// given. Plenty of managed switches and routers still ship with them.
+var DefaultCommunities = []string{"public", "private"}
+
+// sysDescr.0 (1.3.6.1.2.1.1.1.0), the free-text device description
+var sysDescrOID = asn1.ObjectIdentifier{1, 3, 6, 1, 2, 1, 1, 1, 0}
+
+// SNMP PDU tags (context-specific, constructed)
+const (
+	snmpGetRequest  = 0
+	snmpGetResponse = 2
+)
+
+// snmpMessage is an SNMPv2c message. The PDU is kept raw since its tag
+// depends on the PDU type.
+type snmpMessage struct {
+	Version   int
+	Community []byte
+	PDU       asn1.RawValue
+}
+
+type snmpPDU struct {
+	RequestID   int32
+	ErrorStatus int
+	ErrorIndex  int
+	VarBinds    []snmpVarBind
+}
+
+type snmpVarBind struct {
+	Name  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// ProbeSNMP tries communities against host using the default configuration
+func ProbeSNMP(host string, communities []string) []models.SNMPResult {
+	return New(DefaultConfig()).ProbeSNMP(host, communities)
+}
+
+// ProbeSNMP sends an SNMPv2c GetRequest for sysDescr.0 to UDP port 161 on
+// host with each community string in turn (DefaultCommunities if none are
+// given) and returns the ones the agent answered. Agents silently drop
+// requests with a wrong community, so an empty result means either no SNMP
+// agent or none of the communities are valid.
+func (s *Scanner) ProbeSNMP(host string, communities []string) []models.SNMPResult {
+	const timeout = 2 * time.Second
+
+	if len(communities) == 0 {
+		communities = DefaultCommunities
+	}
+
+	var results []models.SNMPResult
+	for _, community := range communities {
+		descr, err := s.snmpGet(host, community, timeout)
+		if err != nil {
+			continue
+		}
+		results = append(results, models.SNMPResult{
+			Community: community,
+			SysDescr:  descr,
+		})
+	}
+	return results
+}
+
+// snmpGet asks host for sysDescr.0 using community and returns the value
+func (s *Scanner) snmpGet(host, community string, timeout time.Duration) (string, error) {
+	requestID := rand.Int32()
+	request, err := marshalSNMPGet(community, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := s.dialUDP(hostPort(host, 161))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(s.jitter(timeout)))
+	if _, err := conn.Write(request); err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return "", err
+		}
+		// Skip stray datagrams rather than giving up on the agent
+		if descr, err := parseSNMPResponse(buffer[:n], community, requestID); err == nil {
+			return descr, nil
+		}
+	}
+}
+
+// marshalSNMPGet builds a GetRequest for sysDescr.0
+func marshalSNMPGet(community string, requestID int32) ([]byte, error) {
+	pdu, err := asn1.Marshal(snmpPDU{
+		RequestID: requestID,
+		VarBinds: []snmpVarBind{{
+			Name:  sysDescrOID,
+			Value: asn1.RawValue{Tag: asn1.TagNull},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the PDU's SEQUENCE tag for the GetRequest tag
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(pdu, &raw); err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(snmpMessage{
+		Version:   1, // SNMPv2c
+		Community: []byte(community),
+		PDU: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        snmpGetRequest,
+			IsCompound: true,
+			Bytes:      raw.Bytes,
+		},
+	})
+}
+
+// parseSNMPResponse extracts sysDescr.0 from a GetResponse, checking it
+// answers the request that was sent
+func parseSNMPResponse(data []byte, community string, requestID int32) (string, error) {
+	var msg snmpMessage
+	if _, err := asn1.Unmarshal(data, &msg); err != nil {
+		return "", err
+	}
+	if msg.PDU.Class != asn1.ClassContextSpecific || msg.PDU.Tag != snmpGetResponse {
+		return "", errors.New("snmp: not a GetResponse")
+	}
+	if !bytes.Equal(msg.Community, []byte(community)) {
+		return "", errors.New("snmp: community mismatch")
+	}
+
+	// Re-tag the PDU as a SEQUENCE so it unmarshals into snmpPDU
+	seq := append([]byte{0x30}, msg.PDU.FullBytes[1:]...)
+	var pdu snmpPDU
+	if _, err := asn1.Unmarshal(seq, &pdu); err != nil {
+		return "", err
+	}
+	switch {
+	case pdu.RequestID != requestID:
+		return "", errors.New("snmp: request ID mismatch")
+	case pdu.ErrorStatus != 0:
+		return "", fmt.Errorf("snmp: error status %d", pdu.ErrorStatus)
+	case len(pdu.VarBinds) == 0 || pdu.VarBinds[0].Value.Tag != asn1.TagOctetString:
+		return "", errors.New("snmp: no sysDescr in response")
+	}
+
+	return string(pdu.VarBinds[0].Value.Bytes), nil
+}
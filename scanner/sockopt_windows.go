@@ -0,0 +1,17 @@
+//go:build windows
+
+package scanner
+
+import "syscall"
+
+// reuseAddr sets SO_REUSEADDR so concurrent dials can share a fixed source
+// port
+func reuseAddr(_, _ string, c syscall.RawConn) error {
+	var err error
+	if cerr := c.Control(func(fd uintptr) {
+		err = syscall.SetsockoptInt(syscall.Handle(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"netscan/models"
+	"netscan/utils"
+	"os"
+	"time"
+)
+
+// HostSpool is a set of discovery results stored on disk as JSON lines
+// rather than in memory. Read them back with Each and remove the file with
+// Close when done.
+type HostSpool struct {
+	path  string
+	count int
+	live  int
+}
+
+// DiscoverToSpool runs discovery using the default configuration, spooling
+// results to a file in dir
+func DiscoverToSpool(network string, ports []int, dir string) (*HostSpool, error) {
+	return New(DefaultConfig()).DiscoverToSpool(network, ports, dir)
+}
+
+// DiscoverToSpool works like NetworkDiscovery but writes each result to a
+// temporary JSONL file in dir (os.TempDir if empty) as soon as its host is
+// done, so peak memory stays bounded by the batch size no matter how large
+// the range. Results aren't printed or sorted; the spool returns
+// them in the order they were found.
+func (s *Scanner) DiscoverToSpool(network string, ports []int, dir string) (*HostSpool, error) {
+	fmt.Fprintf(s.out, "\n🔍 Network discovery on %s (spooling to disk)\n", network)
+
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(dir, "netscan-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	spool := &HostSpool{path: f.Name()}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	var writeErr error
+
+	start := time.Now()
+	s.discoverBatches(context.Background(), ips, ports, func(host models.HostResult) {
+		if writeErr != nil {
+			return
+		}
+		writeErr = enc.Encode(host)
+		spool.count++
+		if host.Alive {
+			spool.live++
+		}
+	})
+	if writeErr == nil {
+		writeErr = w.Flush()
+	}
+
+	if err := errors.Join(writeErr, f.Close()); err != nil {
+		os.Remove(spool.path)
+		return nil, fmt.Errorf("spooling results: %w", err)
+	}
+
+	fmt.Fprintf(s.out, "\n✅ Discovery completed in %v\n", time.Since(start))
+	fmt.Fprintf(s.out, "📊 Found %d live hosts out of %d scanned, spooled to %s\n", spool.live, len(ips), spool.path)
+
+	return spool, nil
+}
+
+// Path returns the spool file's location
+func (sp *HostSpool) Path() string {
+	return sp.path
+}
+
+// Len returns how many hosts the spool holds
+func (sp *HostSpool) Len() int {
+	return sp.count
+}
+
+// Each decodes the spooled hosts one at a time and calls fn with each,
+// stopping at the first error fn returns
+func (sp *HostSpool) Each(fn func(models.HostResult) error) error {
+	f, err := os.Open(sp.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var host models.HostResult
+		if err := dec.Decode(&host); err != nil {
+			return fmt.Errorf("reading spool %s: %w", sp.path, err)
+		}
+		if err := fn(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close deletes the spool file
+func (sp *HostSpool) Close() error {
+	return os.Remove(sp.path)
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"netscan/models"
+	"netscan/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PingSweep finds live hosts on network using the default configuration
+func PingSweep(network string) []models.HostResult {
+	return New(DefaultConfig()).PingSweep(network)
+}
+
+// PingSweep finds live hosts on network without scanning their ports. Batch
+// processing keeps very large networks manageable.
+func (s *Scanner) PingSweep(network string) []models.HostResult {
+	fmt.Fprintf(s.out, "\n🔍 Batch scanning network: %s\n", network)
+
+	ips, err := utils.GenerateIPs(network)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return nil
+	}
+	ips = utils.ExcludeIPs(ips, s.cfg.Exclude)
+	maxConcurrent := fitFDs(500, len(pingPorts))
+	batchSize := orDefault(s.cfg.SweepBatchSize, DefaultSweepBatchSize)
+
+	var collector ResultCollector[models.HostResult]
+
+	start := time.Now()
+
+	for i := 0; i < len(ips); i += batchSize {
+		end := i + batchSize
+		if end > len(ips) {
+			end = len(ips)
+		}
+
+		batch := ips[i:end]
+		batchStart := time.Now()
+
+		var wg sync.WaitGroup
+		results := make(chan models.HostResult, len(batch))
+		sem := make(chan struct{}, maxConcurrent)
+
+		for _, ip := range batch {
+			wg.Add(1)
+			go func(ip string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				alive, latency, _ := s.pingHostFast(context.Background(), ip)
+
+				if alive {
+					host := models.HostResult{
+						IP:      ip,
+						Alive:   alive,
+						Latency: latency,
+					}
+					s.resolvePTR(context.Background(), &host)
+					results <- host
+				}
+			}(ip)
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		var batchHosts []models.HostResult
+		for result := range results {
+			batchHosts = append(batchHosts, result)
+		}
+
+		collector.Add(batchHosts...)
+
+		batchElapsed := time.Since(batchStart)
+		fmt.Fprintf(s.out, "📈 Batch %d/%d: %d hosts found in %v\n",
+			(i/batchSize)+1, (len(ips)+batchSize-1)/batchSize,
+			len(batchHosts), batchElapsed)
+	}
+
+	elapsed := time.Since(start)
+
+	allHosts := collector.Snapshot()
+	sort.Slice(allHosts, func(i, j int) bool {
+		return utils.CompareIPs(allHosts[i].IP, allHosts[j].IP)
+	})
+
+	fmt.Fprintf(s.out, "\n✅ Batch scan completed in %v\n", elapsed)
+	fmt.Fprintf(s.out, "📊 Found %d live hosts out of %d scanned:\n\n", len(allHosts), len(ips))
+	s.printLive(allHosts)
+
+	return allHosts
+}
+
+// printLive lists the hosts a sweep found, one line each
+func (s *Scanner) printLive(hosts []models.HostResult) {
+	for _, host := range hosts {
+		fmt.Fprintf(s.out, "🟢 %-15s", host.IP)
+		if host.MAC != "" {
+			fmt.Fprintf(s.out, " %s", host.MAC)
+		}
+		if host.Vendor != "" {
+			fmt.Fprintf(s.out, " [%s]", host.Vendor)
+		}
+		if host.Hostname != "" {
+			fmt.Fprintf(s.out, " (%s)", host.Hostname)
+		}
+		fmt.Fprintf(s.out, " (%.2fms)\n", float64(host.Latency.Nanoseconds())/1000000)
+	}
+}
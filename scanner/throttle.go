@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"context"
+	"net"
+
+	"golang.org/x/time/rate"
+)
+
+// throttledConn limits how fast a connection is read, sharing one byte
+// budget across every connection of a scan
+type throttledConn struct {
+	net.Conn
+	limiter *rate.Limiter
+}
+
+// throttle wraps conn so reads respect MaxBytesPerSec, or returns it as is
+// if there's no limit
+func (s *Scanner) throttle(conn net.Conn) net.Conn {
+	if s.bandwidth == nil {
+		return conn
+	}
+	return &throttledConn{Conn: conn, limiter: s.bandwidth}
+}
+
+// waitRate waits until MaxConnsPerSec allows another connection, returning
+// early with ctx's error if it ends first
+func (s *Scanner) waitRate(ctx context.Context) error {
+	if s.connRate == nil {
+		return nil
+	}
+	if err := s.connRate.Wait(ctx); err != nil {
+		// Wait also fails up front when ctx's deadline is too close
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// Read reads at most one burst's worth of bytes, then waits until the
+// shared budget has room for them. Unread data stays in the kernel, where
+// TCP flow control slows the sender down.
+func (c *throttledConn) Read(b []byte) (int, error) {
+	if burst := c.limiter.Burst(); len(b) > burst {
+		b = b[:burst]
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.limiter.WaitN(context.Background(), n)
+	}
+	return n, err
+}
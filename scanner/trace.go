@@ -0,0 +1,222 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"netscan/models"
+	"netscan/utils"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// DefaultMaxHops is how far a traceroute goes when no limit is given, as
+// with the traceroute(8) default
+const DefaultMaxHops = 30
+
+// ErrTraceProxy is returned when a traceroute is asked to run through a
+// Proxy or Dialer, which can't carry its probes
+var ErrTraceProxy = errors.New("traceroute can't run through a proxy")
+
+// tracer sends one probe to its destination with the given TTL and returns
+// the address that answered, or nil if nothing did before deadline, and
+// whether the answer came from the destination itself
+type tracer interface {
+	probe(ttl int, deadline time.Time) (peer net.IP, reached bool, err error)
+	Close() error
+}
+
+// Traceroute traces the path to host using the default configuration
+func Traceroute(host string, maxHops int) ([]models.Hop, error) {
+	return New(DefaultConfig()).Traceroute(host, maxHops)
+}
+
+// Traceroute finds the routers on the path to host by sending probes with
+// a TTL of 1, 2, 3 and so on, each of which the router where it runs out
+// answers with an ICMP time exceeded. It stops at the destination or after
+// maxHops hops (DefaultMaxHops if 0), waiting up to twice PingTimeout for
+// each; a hop that never answers is returned with no IP.
+//
+// Probes are ICMP echo requests, which need a raw socket (root or
+// CAP_NET_RAW). Without one it falls back to UDP probes to ports from 33434
+// up, reading the ICMP errors they provoke from the socket's error queue,
+// which needs no privileges but only works on Linux; elsewhere it fails
+// with ErrICMPUnavailable. It can't run through a Proxy or Dialer.
+func (s *Scanner) Traceroute(host string, maxHops int) ([]models.Hop, error) {
+	return s.traceroute(context.Background(), host, maxHops)
+}
+
+// traceroute is Traceroute, stopping early when ctx ends
+func (s *Scanner) traceroute(ctx context.Context, host string, maxHops int) ([]models.Hop, error) {
+	if s.cfg.Proxy != nil || s.cfg.Dialer != nil {
+		return nil, ErrTraceProxy
+	}
+	if maxHops <= 0 {
+		maxHops = DefaultMaxHops
+	}
+
+	host = utils.BareHost(host)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addrs, err := s.resolver().LookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		ip = addrs[0]
+	}
+	if s.excluded(ip.String()) {
+		return nil, fmt.Errorf("%s: %w", ip, ErrExcluded)
+	}
+
+	var t tracer
+	if it, err := newICMPTracer(ip); err == nil {
+		t = it
+	} else if t, err = newUDPTracer(ip); err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	var hops []models.Hop
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		s.waitWhilePaused(ctx)
+		if err := s.waitRate(ctx); err != nil {
+			return hops, err
+		}
+
+		deadline := time.Now().Add(2 * s.pingTimeout())
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		start := time.Now()
+		peer, reached, err := t.probe(ttl, deadline)
+		if err != nil {
+			return hops, err
+		}
+
+		hop := models.Hop{TTL: ttl}
+		if peer != nil {
+			hop.IP = peer.String()
+			hop.RTT = time.Since(start)
+		}
+		hops = append(hops, hop)
+		if reached || ctx.Err() != nil {
+			break
+		}
+	}
+	return hops, ctx.Err()
+}
+
+// icmpTracer probes with ICMP echo requests over a raw socket, which also
+// receives the time exceeded errors they provoke
+type icmpTracer struct {
+	conn *icmp.PacketConn
+	dst  net.IP
+	v4   bool
+	id   int
+}
+
+// newICMPTracer opens a raw ICMP socket for tracing the path to dst.
+// Datagram ICMP sockets aren't tried since they don't deliver ICMP errors.
+func newICMPTracer(dst net.IP) (*icmpTracer, error) {
+	v4 := dst.To4() != nil
+	network, addr := "ip4:icmp", "0.0.0.0"
+	if !v4 {
+		network, addr = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrICMPUnavailable, err)
+	}
+	return &icmpTracer{conn: conn, dst: dst, v4: v4, id: os.Getpid() & 0xffff}, nil
+}
+
+func (t *icmpTracer) Close() error {
+	return t.conn.Close()
+}
+
+func (t *icmpTracer) probe(ttl int, deadline time.Time) (net.IP, bool, error) {
+	echoType, replyType, proto := icmp.Type(ipv4.ICMPTypeEcho), icmp.Type(ipv4.ICMPTypeEchoReply), 1
+	if t.v4 {
+		if err := t.conn.IPv4PacketConn().SetTTL(ttl); err != nil {
+			return nil, false, err
+		}
+	} else {
+		echoType, replyType, proto = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, 58
+		if err := t.conn.IPv6PacketConn().SetHopLimit(ttl); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// The sequence number is the TTL, so an error quoting the probe says
+	// which hop it came from
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: t.id, Seq: ttl, Data: []byte("netscan")},
+	}
+	wire, err := msg.Marshal(nil)
+	if err != nil {
+		return nil, false, err
+	}
+	t.conn.SetDeadline(deadline)
+	if _, err := t.conn.WriteTo(wire, &net.IPAddr{IP: t.dst}); err != nil {
+		return nil, false, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+		from, ok := peer.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		switch body := reply.Body.(type) {
+		case *icmp.Echo:
+			if reply.Type == replyType && body.ID == t.id && body.Seq == ttl && from.IP.Equal(t.dst) {
+				return from.IP, true, nil
+			}
+		case *icmp.TimeExceeded:
+			if t.quotesProbe(body.Data, ttl) {
+				return from.IP, false, nil
+			}
+		case *icmp.DstUnreach:
+			// The destination, or a router refusing to forward, answered
+			if t.quotesProbe(body.Data, ttl) {
+				return from.IP, true, nil
+			}
+		}
+	}
+}
+
+// quotesProbe reports whether data, the original datagram quoted in an ICMP
+// error, is this tracer's probe with the given TTL
+func (t *icmpTracer) quotesProbe(data []byte, ttl int) bool {
+	header := ipv6.HeaderLen
+	if t.v4 {
+		if len(data) == 0 {
+			return false
+		}
+		header = int(data[0]&0x0f) << 2
+	}
+	if len(data) < header+8 {
+		return false
+	}
+	echo := data[header:]
+	return int(binary.BigEndian.Uint16(echo[4:6])) == t.id && int(binary.BigEndian.Uint16(echo[6:8])) == ttl
+}
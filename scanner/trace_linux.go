@@ -0,0 +1,148 @@
+//go:build linux
+
+package scanner
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// traceBasePort is the UDP port the first hop's probe goes to, with each
+// hop after it one higher, as with traceroute(8)
+const traceBasePort = 33434
+
+// sock_extended_err origins and the ICMP types reported through them
+const (
+	eeOriginICMP    = 2
+	eeOriginICMP6   = 3
+	icmpUnreach     = 3
+	icmpTimeExc     = 11
+	icmp6Unreach    = 1
+	icmp6TimeExc    = 3
+	sockExtErrLen   = 16 // struct sock_extended_err, ahead of the offender's address
+	sockaddrInAddr  = 4  // offset of the address in a sockaddr_in
+	sockaddrIn6Addr = 8  // offset of the address in a sockaddr_in6
+)
+
+// udpTracer probes with UDP datagrams to unlikely ports. Linux queues the
+// ICMP errors they provoke on the socket with IP_RECVERR, naming the router
+// that sent each one, so no raw socket is needed.
+type udpTracer struct {
+	dst net.IP
+	v4  bool
+}
+
+func newUDPTracer(dst net.IP) (tracer, error) {
+	return &udpTracer{dst: dst, v4: dst.To4() != nil}, nil
+}
+
+func (t *udpTracer) Close() error {
+	return nil
+}
+
+func (t *udpTracer) probe(ttl int, deadline time.Time) (net.IP, bool, error) {
+	// A fresh socket per hop keeps a late error from one hop out of the
+	// next one's queue
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: t.dst, Port: traceBasePort + ttl - 1})
+	if err != nil {
+		return nil, false, err
+	}
+	defer conn.Close()
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil, false, err
+	}
+
+	level, ttlOpt, recvErr := syscall.IPPROTO_IP, syscall.IP_TTL, syscall.IP_RECVERR
+	if !t.v4 {
+		level, ttlOpt, recvErr = syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, syscall.IPV6_RECVERR
+	}
+	var optErr error
+	if err := raw.Control(func(fd uintptr) {
+		if optErr = syscall.SetsockoptInt(int(fd), level, ttlOpt, ttl); optErr == nil {
+			optErr = syscall.SetsockoptInt(int(fd), level, recvErr, 1)
+		}
+	}); err != nil {
+		return nil, false, err
+	}
+	if optErr != nil {
+		return nil, false, optErr
+	}
+
+	conn.SetDeadline(deadline)
+	if _, err := conn.Write([]byte("netscan")); err != nil {
+		return nil, false, err
+	}
+
+	// The error queue signals as readable, so the poller wakes the callback
+	// when an ICMP error arrives
+	var (
+		peer    net.IP
+		reached bool
+		readErr error
+	)
+	buf, oob := make([]byte, 512), make([]byte, 512)
+	err = raw.Read(func(fd uintptr) bool {
+		_, oobn, _, _, err := syscall.Recvmsg(int(fd), buf, oob, syscall.MSG_ERRQUEUE)
+		if err == syscall.EAGAIN {
+			return false
+		}
+		if err != nil {
+			readErr = err
+			return true
+		}
+		peer, reached = t.parseError(oob[:oobn])
+		return true
+	})
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return peer, reached, readErr
+}
+
+// parseError returns the router that sent the ICMP error in oob, control
+// messages read from a socket's error queue, and whether it was the
+// destination saying it got there, e.g. with port unreachable
+func (t *udpTracer) parseError(oob []byte) (net.IP, bool) {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, false
+	}
+	for _, msg := range msgs {
+		data := msg.Data
+		if len(data) < sockExtErrLen {
+			continue
+		}
+		origin, icmpType := data[4], data[5]
+
+		var addrAt, addrLen int
+		var exceeded, unreach byte
+		switch {
+		case msg.Header.Type == syscall.IP_RECVERR && origin == eeOriginICMP:
+			addrAt, addrLen, exceeded, unreach = sockaddrInAddr, net.IPv4len, icmpTimeExc, icmpUnreach
+		case msg.Header.Type == syscall.IPV6_RECVERR && origin == eeOriginICMP6:
+			addrAt, addrLen, exceeded, unreach = sockaddrIn6Addr, net.IPv6len, icmp6TimeExc, icmp6Unreach
+		default:
+			continue
+		}
+		offender := data[sockExtErrLen:]
+		if len(offender) < addrAt+addrLen {
+			continue
+		}
+		from := net.IP(append([]byte(nil), offender[addrAt:addrAt+addrLen]...))
+
+		switch icmpType {
+		case exceeded:
+			return from, false
+		case unreach:
+			return from, true
+		}
+	}
+	return nil, false
+}
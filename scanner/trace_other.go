@@ -0,0 +1,15 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// newUDPTracer needs the Linux socket error queue to learn which router
+// dropped each probe
+func newUDPTracer(_ net.IP) (tracer, error) {
+	return nil, fmt.Errorf("%w: unprivileged traceroute isn't supported on %s", ErrICMPUnavailable, runtime.GOOS)
+}
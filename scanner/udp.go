@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"netscan/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// UDPServices names the services usually found on UDP ports. LookupService
+// falls back to it like CommonServices.
+var UDPServices = map[int]string{
+	53:   "DNS",
+	67:   "DHCP",
+	69:   "TFTP",
+	123:  "NTP",
+	137:  "NetBIOS-NS",
+	161:  "SNMP",
+	500:  "IKE",
+	514:  "Syslog",
+	1900: "SSDP",
+	5353: "mDNS",
+}
+
+// udpReplyMax is how much of a UDP reply is kept when BannerMaxBytes is unset
+const udpReplyMax = 512
+
+// udpProbes build payloads that get a reply from common UDP services.
+// Other ports get an empty datagram, which many services ignore.
+var udpProbes = map[int]func() []byte{
+	53:   dnsProbe,
+	123:  ntpProbe,
+	137:  func() []byte { return nbstatQuery(uint16(rand.IntN(1 << 16))) },
+	161:  snmpProbe,
+	5353: mdnsProbe,
+}
+
+// ScanPortUDP scans a single UDP port using the default configuration
+func ScanPortUDP(host string, port int) models.PortResult {
+	return New(DefaultConfig()).ScanPortUDP(host, port)
+}
+
+// ScanPortUDP sends a probe to a UDP port, one the service is known to
+// answer where there is one, and waits up to DialTimeout for a reply. A
+// reply means open, an ICMP port unreachable means closed, and silence
+// means open|filtered, since UDP services often ignore probes they don't
+// understand just as a firewall would.
+func (s *Scanner) ScanPortUDP(host string, port int) models.PortResult {
+	result := models.PortResult{
+		Port:     port,
+		Protocol: models.ProtoUDP,
+		State:    models.StateFiltered,
+		Service:  s.udpServiceName(port),
+	}
+
+	conn, err := s.dialUDP(hostPort(host, port))
+	if err != nil {
+		return result
+	}
+	defer conn.Close()
+
+	var probe []byte
+	if build, ok := udpProbes[port]; ok {
+		probe = build()
+	}
+	conn.SetDeadline(time.Now().Add(s.dialTimeout()))
+	if _, err := conn.Write(probe); err != nil {
+		if isPortUnreachable(err) {
+			result.State = models.StateClosed
+		}
+		return result
+	}
+
+	buffer := make([]byte, orDefault(s.cfg.BannerMaxBytes, udpReplyMax))
+	n, err := conn.Read(buffer)
+	switch {
+	case n > 0:
+		result.Open = true
+		result.State = models.StateOpen
+		result.Banner = printable(buffer[:n])
+	case isPortUnreachable(err):
+		result.State = models.StateClosed
+	default:
+		result.State = models.StateOpenFiltered
+	}
+	return result
+}
+
+// ScanPortsUDP scans UDP ports on target, prints the ones that replied (and
+// the others too with IncludeClosed) and returns them sorted by port
+// number. Silent ports cost a full DialTimeout each, so ports are scanned
+// concurrently.
+func (s *Scanner) ScanPortsUDP(target string, ports []int) []models.PortResult {
+	const maxConcurrent = 100
+
+	fmt.Fprintf(s.out, "\n🔍 Scanning %s for %d UDP ports...\n", target, len(ports))
+	if s.excluded(target) {
+		fmt.Fprintf(s.out, "⛔ %s is excluded from scanning\n", target)
+		return nil
+	}
+
+	start := time.Now()
+	var collector ResultCollector[models.PortResult]
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.hostConcurrency(maxConcurrent))
+	for _, port := range scanOrder(s, ports) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(port int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if result := s.ScanPortUDP(target, port); result.Open || s.cfg.IncludeClosed {
+				collector.Add(result)
+			}
+		}(port)
+	}
+	wg.Wait()
+
+	results := collector.Snapshot()
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Port < results[j].Port
+	})
+
+	fmt.Fprintf(s.out, "\n✅ UDP scan completed in %v\n", time.Since(start))
+	fmt.Fprintf(s.out, "📊 Found %d open UDP ports:\n\n", countOpen(results))
+	for _, port := range results {
+		s.printPort(s.out, port)
+	}
+	return results
+}
+
+// udpServiceName returns the name of the service usually found on UDP port,
+// or "" if it isn't known. Names in ScanConfig.Services take precedence.
+func (s *Scanner) udpServiceName(port int) string {
+	if name := s.cfg.Services[port]; name != "" {
+		return name
+	}
+	return LookupService(port, models.ProtoUDP)
+}
+
+func countOpen(results []models.PortResult) int {
+	open := 0
+	for _, result := range results {
+		if result.Open {
+			open++
+		}
+	}
+	return open
+}
+
+// printable reduces a binary reply to its runs of printable ASCII, for a
+// readable banner
+func printable(data []byte) string {
+	var b strings.Builder
+	run := 0
+	for _, c := range data {
+		if c >= 0x20 && c < 0x7f {
+			b.WriteByte(c)
+			run++
+			continue
+		}
+		if run > 0 {
+			b.WriteByte(' ')
+			run = 0
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// dnsProbe asks for the root name servers, which any resolver answers
+func dnsProbe() []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.IntN(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName("."),
+			Type:  dnsmessage.TypeNS,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, _ := msg.Pack()
+	return packed
+}
+
+// ntpProbe is an NTPv4 client request
+func ntpProbe() []byte {
+	packet := make([]byte, 48)
+	packet[0] = 4<<3 | 3 // version 4, mode 3 (client)
+	return packet
+}
+
+// snmpProbe asks for sysDescr.0 with the "public" community
+func snmpProbe() []byte {
+	packet, _ := marshalSNMPGet("public", rand.Int32())
+	return packet
+}
+
+// mdnsProbe asks for the service types a responder advertises
+func mdnsProbe() []byte {
+	packet, _ := mdnsQuery("_services._dns-sd._udp.local.")
+	return packet
+}
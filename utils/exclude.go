@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseExclusion returns the network covered by an IP address or CIDR
+// network, or nil if s is neither
+func ParseExclusion(s string) *net.IPNet {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet
+	}
+	ip := net.ParseIP(BareHost(s))
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// ParseExclusions parses a comma-separated list of IP addresses and CIDR
+// networks to exclude, e.g. "192.168.1.1,192.168.1.0/28". Any entry that
+// isn't an address or network is an error, so a typo can't quietly leave a
+// protected host unlisted.
+func ParseExclusions(list string) ([]string, error) {
+	var exclusions []string
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if ParseExclusion(entry) == nil {
+			return nil, fmt.Errorf("invalid address or network %q", entry)
+		}
+		exclusions = append(exclusions, entry)
+	}
+	return exclusions, nil
+}
+
+// Excluded reports whether host is an IP address covered by one of
+// exclusions, IP addresses and CIDR networks. Host names are never
+// excluded, since they'd have to be resolved to tell.
+func Excluded(host string, exclusions []string) bool {
+	ip := net.ParseIP(BareHost(host))
+	if ip == nil {
+		return false
+	}
+	for _, entry := range exclusions {
+		if ipNet := ParseExclusion(entry); ipNet != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludeIPs returns ips, as from GenerateIPs, without the ones covered by
+// exclusions, keeping their order
+func ExcludeIPs(ips, exclusions []string) []string {
+	if len(exclusions) == 0 {
+		return ips
+	}
+	var kept []string
+	for _, ip := range ips {
+		if !Excluded(ip, exclusions) {
+			kept = append(kept, ip)
+		}
+	}
+	return kept
+}
+
+// ExcludePorts returns ports, as from ParsePortRange, without the ones in
+// excluded, keeping their order
+func ExcludePorts(ports, excluded []int) []int {
+	if len(excluded) == 0 {
+		return ports
+	}
+	skip := make(map[int]bool, len(excluded))
+	for _, port := range excluded {
+		skip[port] = true
+	}
+	var kept []int
+	for _, port := range ports {
+		if !skip[port] {
+			kept = append(kept, port)
+		}
+	}
+	return kept
+}
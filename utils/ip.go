@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NextIP returns the address following ip, carrying into higher octets as
+// needed (192.168.0.255 -> 192.168.1.0). IPv4 addresses are returned in
+// their 4-byte form. The highest address wraps around to the lowest.
+func NextIP(ip net.IP) net.IP {
+	next := normalize(ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// PrevIP returns the address preceding ip, borrowing from higher octets as
+// needed (192.168.1.0 -> 192.168.0.255). IPv4 addresses are returned in
+// their 4-byte form. The lowest address wraps around to the highest.
+func PrevIP(ip net.IP) net.IP {
+	prev := normalize(ip)
+	for i := len(prev) - 1; i >= 0; i-- {
+		prev[i]--
+		if prev[i] != 0xff {
+			break
+		}
+	}
+	return prev
+}
+
+// normalize returns a copy of ip, shortened to 4 bytes if it's IPv4
+func normalize(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	out := make(net.IP, len(ip))
+	copy(out, ip)
+	return out
+}
+
+// ErrLoopbackRange is returned when asked to enumerate a large chunk of
+// 127.0.0.0/8. Every address in it is this machine, so sweeping millions of
+// them is almost always a mistake.
+var ErrLoopbackRange = errors.New("loopback range too large: every address in 127.0.0.0/8 is this machine, scan 127.0.0.1 instead")
+
+// Smallest loopback prefix GenerateIPs will enumerate
+const minLoopbackPrefix = 24
+
+// Smallest IPv4 prefix GenerateIPs will enumerate, the same 65,536
+// addresses as maxRangeSize. This caps every IPv4 network, not only
+// loopback ones: a /8 alone would be 16 million strings built before the
+// first probe. Wider networks have to be split into /16s.
+const minIPv4Prefix = 16
+
+// Smallest IPv6 prefix GenerateIPs will enumerate. Anything wider has far
+// too many addresses to sweep; a /64 alone has 2^64.
+const minIPv6Prefix = 112
+
+// GenerateIPs lists the host addresses in a CIDR network. Host bits in the
+// input are ignored, so 10.0.0.5/24 enumerates 10.0.0.1-10.0.0.254 just
+// like 10.0.0.0/24. The network and broadcast addresses are skipped except
+// for /31 and /32, which have none; in IPv6 networks, which have no
+// broadcast, only the first (subnet-router anycast) address is skipped.
+// IPv4 networks wider than a /16 and IPv6 networks wider than a /112, both
+// 65,536 addresses, are rejected whatever their address, and loopback
+// ranges wider than a /24 with ErrLoopbackRange. A bare address, bracketed
+// or not for IPv6, is returned as is.
+func GenerateIPs(network string) ([]string, error) {
+	network = strings.TrimSpace(network)
+
+	if ip := net.ParseIP(BareHost(network)); ip != nil {
+		return []string{ip.String()}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(network)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %q: expected CIDR notation like 192.168.1.0/24", network)
+	}
+
+	// ParseCIDR masks the host bits, so this is the network address
+	first := normalize(ipnet.IP)
+	ones, bits := ipnet.Mask.Size()
+	v6 := len(first) == net.IPv6len
+	if v6 && ones < minIPv6Prefix {
+		return nil, fmt.Errorf("invalid network %q: IPv6 networks wider than /%d are too large to enumerate", network, minIPv6Prefix)
+	}
+	if first.IsLoopback() && !v6 && ones < minLoopbackPrefix {
+		return nil, ErrLoopbackRange
+	}
+	if !v6 && ones < minIPv4Prefix {
+		return nil, fmt.Errorf("invalid network %q: IPv4 networks wider than /%d are too large to enumerate, split it into /%d networks", network, minIPv4Prefix, minIPv4Prefix)
+	}
+	size := uint64(1) << uint(bits-ones)
+
+	var ips []string
+	ip := first
+	for i := uint64(0); i < size; i++ {
+		if size <= 2 || (i != 0 && (v6 || i != size-1)) {
+			ips = append(ips, ip.String())
+		}
+		ip = NextIP(ip)
+	}
+
+	return ips, nil
+}
+
+// CompareIPs reports whether ip1 sorts before ip2 numerically, with IPv4
+// addresses ahead of IPv6 ones. Anything that isn't an address (e.g. a
+// hostname) falls back to string order.
+func CompareIPs(ip1, ip2 string) bool {
+	a := net.ParseIP(ip1)
+	b := net.ParseIP(ip2)
+	if a == nil || b == nil {
+		return ip1 < ip2
+	}
+	if v4a, v4b := a.To4() != nil, b.To4() != nil; v4a != v4b {
+		return v4a
+	}
+	return bytes.Compare(a.To16(), b.To16()) < 0
+}
+
+// BareHost strips the brackets from an IPv6 literal such as [2001:db8::1],
+// ready for net.JoinHostPort. Anything else is returned unchanged.
+func BareHost(host string) string {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+	return host
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNextIP(t *testing.T) {
+	tests := []struct {
+		ip, want string
+	}{
+		{"192.168.0.1", "192.168.0.2"},
+		{"192.168.0.255", "192.168.1.0"},
+		{"10.0.255.255", "10.1.0.0"},
+		{"10.255.255.255", "11.0.0.0"},
+		{"255.255.255.255", "0.0.0.0"},
+		{"2001:db8::1", "2001:db8::2"},
+		{"2001:db8::ffff", "2001:db8::1:0"},
+		{"2001:db8:0:ffff:ffff:ffff:ffff:ffff", "2001:db8:1::"},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::"},
+	}
+	for _, tt := range tests {
+		got := NextIP(net.ParseIP(tt.ip))
+		if got.String() != tt.want {
+			t.Errorf("NextIP(%s) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestPrevIP(t *testing.T) {
+	tests := []struct {
+		ip, want string
+	}{
+		{"192.168.0.2", "192.168.0.1"},
+		{"192.168.1.0", "192.168.0.255"},
+		{"10.1.0.0", "10.0.255.255"},
+		{"11.0.0.0", "10.255.255.255"},
+		{"0.0.0.0", "255.255.255.255"},
+		{"2001:db8::2", "2001:db8::1"},
+		{"2001:db8::1:0", "2001:db8::ffff"},
+		{"2001:db8:1::", "2001:db8:0:ffff:ffff:ffff:ffff:ffff"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	}
+	for _, tt := range tests {
+		got := PrevIP(net.ParseIP(tt.ip))
+		if got.String() != tt.want {
+			t.Errorf("PrevIP(%s) = %s, want %s", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestNextIPKeepsInput(t *testing.T) {
+	ip := net.ParseIP("192.168.0.255")
+	NextIP(ip)
+	PrevIP(ip)
+	if ip.String() != "192.168.0.255" {
+		t.Errorf("input changed to %s", ip)
+	}
+}
+
+func TestNextIPReturnsFourBytes(t *testing.T) {
+	// net.ParseIP returns IPv4 addresses in their 16-byte form
+	if got := NextIP(net.ParseIP("192.168.0.1")); len(got) != net.IPv4len {
+		t.Errorf("NextIP returned %d bytes, want %d", len(got), net.IPv4len)
+	}
+	if got := PrevIP(net.ParseIP("192.168.0.1")); len(got) != net.IPv4len {
+		t.Errorf("PrevIP returned %d bytes, want %d", len(got), net.IPv4len)
+	}
+}
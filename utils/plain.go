@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"io"
+	"strings"
+)
+
+// ASCII stand-ins for the emoji used in console output. Forms with the
+// emoji variation selector (U+FE0F) come first so they match before their
+// bare counterparts.
+var plainReplacer = strings.NewReplacer(
+	"🖥️", "[>]",
+	"🖥", "[>]",
+	"⚠️", "[!]",
+	"⚠", "[!]",
+	"🛡️", "[s]",
+	"🛡", "[s]",
+	"🔍", "[*]",
+	"🟢", "[+]",
+	"🔴", "[-]",
+	"🟡", "[?]",
+	"⚫", "[.]",
+	"📈", "[~]",
+	"✅", "[ok]",
+	"📊", "[=]",
+	"❌", "[!]",
+	"📝", "[i]",
+	"📡", "[@]",
+	"⏰", "[t]",
+	"📅", "[t]",
+	"👀", "[*]",
+	"🌐", "[w]",
+	"🔒", "[c]",
+	"💻", "[o]",
+	"⛔", "[x]",
+	"🍯", "[h]",
+	"📍", "[a]",
+)
+
+// PlainText replaces the emoji markers netscan prints with ASCII ones such as
+// [+] and [-], for terminals that can't render emoji, log files and screen
+// readers
+func PlainText(s string) string {
+	return plainReplacer.Replace(s)
+}
+
+// PlainWriter returns a writer that applies PlainText to everything written
+// to w. Each Write should hold whole lines, as fmt's print functions do, so
+// no emoji is split across calls.
+func PlainWriter(w io.Writer) io.Writer {
+	return plainWriter{w}
+}
+
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p plainWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(p.w, PlainText(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
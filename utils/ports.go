@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortGroups names common sets of ports so a port specification can give
+// them by name (web,db,22). Each group is itself a port specification.
+var PortGroups = map[string]string{
+	"web":  "80,443,8080,8443",
+	"db":   "3306,5432,1433,27017,6379",
+	"mail": "25,110,143,465,587,993,995",
+	"all":  "1-65535",
+}
+
+// ParsePortRange parses a port specification: a comma-separated list whose
+// entries are single ports, ranges or PortGroups names (matched regardless
+// of case), mixed freely (22,80,443,8000-8100 or web,db,22).
+// Tokens prefixed with ! are excluded from the result, so 1-65535,!9100,!515
+// scans everything except those ports. Exclusions may be ranges too
+// (!6000-6063). Each port appears once in the result, in the order first
+// given, however many times it was listed. Invalid tokens, including ports
+// outside 1-65535, are skipped; use ParsePortSpec to have them reported.
+func ParsePortRange(portRange string) []int {
+	ports, _ := ParsePortSpec(portRange)
+	return ports
+}
+
+// ParsePortSpec is ParsePortRange, also returning an error naming every
+// invalid token. The ports from the valid tokens are returned either way.
+func ParsePortSpec(spec string) ([]int, error) {
+	var include [][]int
+	var errs []error
+	excluded := make(map[int]bool)
+
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		exclude := strings.HasPrefix(token, "!")
+		ports, err := parsePorts(strings.TrimPrefix(token, "!"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !exclude {
+			include = append(include, ports)
+			continue
+		}
+		for _, port := range ports {
+			excluded[port] = true
+		}
+	}
+
+	var ports []int
+	for _, list := range include {
+		for _, port := range list {
+			if !excluded[port] {
+				ports = append(ports, port)
+				// Skip any later copies
+				excluded[port] = true
+			}
+		}
+	}
+
+	return ports, errors.Join(errs...)
+}
+
+// parsePorts parses a single port (443), a range of them (8000-8100) or
+// the name of a port group (web)
+func parsePorts(token string) ([]int, error) {
+	if group, ok := PortGroups[strings.ToLower(token)]; ok {
+		return ParsePortSpec(group)
+	}
+
+	first, last, isRange := strings.Cut(token, "-")
+	start, err := parsePort(first)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", token)
+	}
+	end := start
+	if isRange {
+		if end, err = parsePort(last); err != nil || end < start {
+			return nil, fmt.Errorf("invalid port range %q", token)
+		}
+	}
+
+	ports := make([]int, 0, end-start+1)
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// parsePort parses a port number, rejecting any outside 1-65535
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d out of range", port)
+	}
+	return port, nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// span returns the ports from start to end inclusive
+func span(start, end int) []int {
+	var ports []int
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"22,8000-8100,443", append(append([]int{22}, span(8000, 8100)...), 443)},
+		{"443,22,443,22", []int{443, 22}},
+		{"8000-8002,8001,7999-8003", []int{8000, 8001, 8002, 7999, 8003}},
+		{" 80 , 443 ", []int{80, 443}},
+		{"1-10,!3-8,!10", []int{1, 2, 9}},
+		{"1,65535", []int{1, 65535}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePortSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParsePortSpec(%q): %v", tt.spec, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePortSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePortSpecRejectsOutOfRange(t *testing.T) {
+	for _, spec := range []string{"0", "65536", "0-10", "65530-65536"} {
+		got, err := ParsePortSpec(spec)
+		if err == nil {
+			t.Errorf("ParsePortSpec(%q) = %v, want an error", spec, got)
+		}
+		if len(got) != 0 {
+			t.Errorf("ParsePortSpec(%q) = %v, want no ports", spec, got)
+		}
+	}
+}
+
+func TestParsePortSpecKeepsValidPorts(t *testing.T) {
+	got, err := ParsePortSpec("22,bogus,80,90-85")
+	if err == nil {
+		t.Fatal("ParsePortSpec: no error for invalid tokens")
+	}
+	for _, token := range []string{"bogus", "90-85"} {
+		if !strings.Contains(err.Error(), token) {
+			t.Errorf("error %q doesn't name %q", err, token)
+		}
+	}
+	if want := []int{22, 80}; !slices.Equal(got, want) {
+		t.Errorf("ParsePortSpec = %v, want %v", got, want)
+	}
+}
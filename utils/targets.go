@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxRangeSize caps how many addresses a single start-end range may expand
+// to, the same as a /16
+const maxRangeSize = 1 << 16
+
+// NormalizeTargets turns a mixed list of targets into a sorted list of
+// unique addresses. Each input may hold several targets separated by
+// commas or whitespace, and each target may be:
+//
+//	192.168.1.10               a single address
+//	192.168.1.0/24             a CIDR network (see GenerateIPs)
+//	192.168.1.10-192.168.1.20  a range between two addresses
+//	192.168.1.10-20            a range within the last octet
+//	2001:db8::1 or [2001:db8::1]  an IPv6 address
+//	2001:db8::/120             an IPv6 network (see GenerateIPs)
+//	fileserver.lan             a hostname, resolved to its IPv4 and IPv6 addresses
+//
+// A target that can't be parsed or resolved produces an error naming it and
+// is skipped; the rest of the list is still processed. Hostnames are looked
+// up with the system resolver.
+func NormalizeTargets(inputs []string) (ips []string, errs []error) {
+	return NormalizeTargetsWith(inputs, net.LookupIP)
+}
+
+// NormalizeTargetsWith is NormalizeTargets, resolving hostnames with lookup,
+// e.g. a net.Resolver's LookupIP, instead of the system resolver
+func NormalizeTargetsWith(inputs []string, lookup func(host string) ([]net.IP, error)) (ips []string, errs []error) {
+	seen := make(map[string]bool)
+
+	for _, input := range inputs {
+		for _, target := range strings.FieldsFunc(input, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+		}) {
+			expanded, err := expandTarget(target, lookup)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("target %q: %w", target, err))
+				continue
+			}
+			for _, ip := range expanded {
+				if !seen[ip] {
+					seen[ip] = true
+					ips = append(ips, ip)
+				}
+			}
+		}
+	}
+
+	sort.Slice(ips, func(i, j int) bool {
+		return CompareIPs(ips[i], ips[j])
+	})
+	return ips, errs
+}
+
+// expandTarget expands one target into the addresses it covers, resolving
+// a hostname with lookup
+func expandTarget(target string, lookup func(string) ([]net.IP, error)) ([]string, error) {
+	switch {
+	case strings.Contains(target, "/"):
+		return GenerateIPs(target)
+	case net.ParseIP(BareHost(target)) != nil:
+		return []string{net.ParseIP(BareHost(target)).String()}, nil
+	case strings.Contains(target, "-"):
+		// Hostnames can contain dashes too, so it's only a range if it
+		// starts with an address
+		if from, _, _ := strings.Cut(target, "-"); net.ParseIP(from) != nil {
+			return expandRange(target)
+		}
+	}
+
+	addrs, err := lookup(target)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.String())
+	}
+	return ips, nil
+}
+
+// expandRange expands 10.0.0.1-10.0.0.20 or 10.0.0.1-20 into every address
+// from start to end inclusive
+func expandRange(target string) ([]string, error) {
+	from, to, _ := strings.Cut(target, "-")
+
+	start := net.ParseIP(from).To4()
+	if start == nil {
+		return nil, fmt.Errorf("invalid range start %q", from)
+	}
+
+	end := net.ParseIP(to).To4()
+	if end == nil {
+		// Short form: only the last octet is given
+		last, err := strconv.Atoi(to)
+		if err != nil || last < 0 || last > 255 {
+			return nil, fmt.Errorf("invalid range end %q", to)
+		}
+		end = normalize(start)
+		end[3] = byte(last)
+	}
+
+	if bytes.Compare(start, end) > 0 {
+		return nil, fmt.Errorf("range start is after its end")
+	}
+	var ips []string
+	for ip := normalize(start); ; ip = NextIP(ip) {
+		if len(ips) == maxRangeSize {
+			return nil, fmt.Errorf("range is larger than %d addresses", maxRangeSize)
+		}
+		ips = append(ips, ip.String())
+		if ip.Equal(end) {
+			break
+		}
+	}
+	return ips, nil
+}